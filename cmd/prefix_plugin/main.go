@@ -0,0 +1,55 @@
+/*
+`prefix_plugin` is a reference snekcheck rule plugin, which requires filenames to start with one of a set of prefixes,
+such as team or product codes. Directories and hidden files, such as `.snekcheck.yaml`, are not checked.
+
+Usage:
+
+	prefix_plugin <prefix> ...
+
+Invalid filenames are fixed by prepending the first prefix.
+
+It is configured in the `plugins` section of a `.snekcheck.yaml` file:
+
+	plugins:
+	  - name: prefix
+	    command: [prefix_plugin, payments_, billing_]
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"snekcheck/internal/plugin"
+	"strings"
+)
+
+// The ID of the plugin's only rule.
+const rule = "required"
+
+// Serves the plugin protocol over standard input and output.
+// Will exit with a non-zero exit code upon failure.
+func main() {
+	prefixes := os.Args[1:]
+	if len(prefixes) == 0 {
+		fmt.Fprintln(os.Stderr, "at least one prefix must be specified")
+		os.Exit(1)
+	}
+
+	serveErr := plugin.Serve(os.Stdin, os.Stdout, func(path plugin.Path) []plugin.Diagnostic {
+		name := filepath.Base(path.Path)
+		if path.Dir || strings.HasPrefix(name, ".") || slices.ContainsFunc(prefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+			return nil
+		}
+		return []plugin.Diagnostic{{
+			Rule:    rule,
+			Message: fmt.Sprintf("%q does not start with any of %s", name, strings.Join(prefixes, ", ")),
+			Fix:     prefixes[0] + name,
+		}}
+	})
+	if serveErr != nil {
+		fmt.Fprintln(os.Stderr, serveErr)
+		os.Exit(1)
+	}
+}
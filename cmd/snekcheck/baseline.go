@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"snekcheck/internal/baseline"
+	"snekcheck/internal/files"
+	"snekcheck/lint"
+
+	"github.com/go-git/go-billy/v5/osfs"
+)
+
+// The baseline subcommand.
+// Records the current invalid paths in the baseline file of the working directory,
+// replacing its entries within the checked paths, and keeping the others.
+// Accepts the same flags as a normal run.
+//
+// Usage:
+//
+//	snekcheck baseline write <flag> ... <path> ...
+func baselineSubcommand(args []string) uint8 {
+	if len(args) == 0 || args[0] != "write" {
+		logger.Error("usage: snekcheck baseline write <flag> ... <path> ...")
+		return 1
+	}
+
+	rootFs := osfs.New("/")
+	pwd, pwdErr := os.Getwd()
+	if pwdErr != nil {
+		panic("could not determine present working directory")
+	}
+
+	_ = flag.CommandLine.Parse(args[1:])
+	result := check(newLinter(parseArgs(rootFs, pwd, flag.Args())), flag.Args())
+
+	var invalidPaths []files.Path
+	for _, path := range result.Paths(lint.Invalid) {
+		invalidPaths = append(invalidPaths, files.NewPath(path))
+	}
+	// Paths filtered by glob patterns from CLI flags are not checked in full, so no entries are removed.
+	var roots []files.Path
+	for _, root := range result.Roots {
+		roots = append(roots, files.NewPath(root))
+	}
+	b := loadBaseline(rootFs, files.NewPath(pwd)).Replace(roots, invalidPaths)
+	if writeErr := b.Write(rootFs); writeErr != nil {
+		logger.Error(writeErr)
+		return 1
+	}
+	logger.Infof("recorded %d invalid paths in %s", b.Len(), baseline.FileName)
+	return 0
+}
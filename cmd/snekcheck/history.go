@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"snekcheck/internal/repo"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// The history subcommand.
+// Reports when the invalid names in a Git repository's history were introduced and fixed.
+//
+// Usage:
+//
+//	snekcheck history [--from <revision>] [--to <revision>] [<path>]
+func history(args []string) uint8 {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	from := flags.String("from", "", "The revision to audit from, exclusive. Defaults to the root commit")
+	to := flags.String("to", "HEAD", "The revision to audit up to, inclusive")
+	_ = flags.Parse(args)
+
+	path := "."
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		path = flags.Arg(0)
+	default:
+		logger.Error("at most one repository path may be specified")
+		return 1
+	}
+
+	r, openErr := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if openErr != nil {
+		logger.Errorf("not a git repository: %s", path)
+		return 1
+	}
+
+	audit, historyErr := repo.History(r, *from, *to, repoLinter(r).IsValid)
+	if historyErr != nil {
+		logger.Error(historyErr)
+		return 1
+	}
+
+	for _, violation := range audit.Current {
+		if violation.Introduced == nil {
+			logger.Print("", "INVALID", violation.Path, "introduced", "before range")
+			continue
+		}
+		logger.Print("", append([]any{"INVALID", violation.Path}, commitKeyvals(violation.Introduced)...)...)
+	}
+	for _, violation := range audit.Fixed {
+		keyvals := []any{"FIXED", violation.Path}
+		if violation.Introduced != nil {
+			keyvals = append(keyvals, commitKeyvals(violation.Introduced)...)
+		}
+		logger.Print("", append(keyvals, "fixed", violation.Fixed.Hash.String()[:7])...)
+	}
+	for _, period := range audit.Trend() {
+		logger.Print("", "month", period.Month.Format("2006-01"), "introduced", period.Introduced, "fixed", period.Fixed)
+	}
+	return 0
+}
+
+// Describes the commit that introduced a violation as logger key-value pairs.
+func commitKeyvals(commit *repo.Commit) []any {
+	return []any{
+		"introduced", commit.Hash.String()[:7],
+		"author", commit.Author.Name,
+		"date", commit.Author.When.Format("2006-01-02"),
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"snekcheck/internal/files"
+	"snekcheck/internal/lsp"
+	"snekcheck/lint"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+)
+
+// The lsp subcommand.
+// Serves diagnostics for invalid names over the Language Server Protocol on stdin and stdout,
+// checking the editor's workspace folders, or else the given paths, which default to the working directory.
+// Accepts the same flags as a normal run.
+//
+// Usage:
+//
+//	snekcheck lsp <flag> ... [<path> ...]
+func lspSubcommand(args []string) uint8 {
+	rootFs := osfs.New("/")
+	pwd, pwdErr := os.Getwd()
+	if pwdErr != nil {
+		panic("could not determine present working directory")
+	}
+
+	_ = flag.CommandLine.Parse(args)
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	options := parseArgs(rootFs, pwd, roots)
+	options.Baseline = baselinePaths(loadBaseline(rootFs, files.NewPath(pwd)))
+	linter := newLinter(options)
+	paths, rootsErr := linter.Roots(roots...)
+	if rootsErr != nil {
+		logger.Error(rootsErr)
+		return 1
+	}
+	var rootPaths []files.Path
+	for _, path := range paths {
+		rootPaths = append(rootPaths, files.NewPath(path))
+	}
+
+	// Stdout carries the protocol, and every checked path would otherwise be logged to stderr.
+	// Failures to check paths are still logged to stderr.
+	errorLogger := log.New(os.Stderr)
+	logger.SetOutput(io.Discard)
+	server := lsp.NewServer(lsp.Options{
+		Check: lspCheck(rootFs, linter, errorLogger),
+		Roots: rootPaths,
+	})
+	if serveErr := server.Serve(os.Stdin, os.Stdout); serveErr != nil {
+		logger.SetOutput(os.Stderr)
+		logger.Error(serveErr)
+		return 1
+	}
+	return 0
+}
+
+// Produces a function that checks the paths of an lsp server with a linter, producing the diagnostics of invalid paths.
+// Paths are checked one at a time, so that a failure only loses the diagnostics of its own path, and is logged.
+// Paths that no longer exist are not invalid.
+func lspCheck(fileSystem billy.Filesystem, linter *lint.Linter, errorLogger *log.Logger) func(paths []files.Path) []lsp.Diagnostic {
+	return func(paths []files.Path) (diagnostics []lsp.Diagnostic) {
+		for _, path := range paths {
+			pathString := strings.Join(path, string(os.PathSeparator))
+			if _, statErr := fileSystem.Lstat(pathString); errors.Is(statErr, fs.ErrNotExist) {
+				continue
+			}
+			result, checkErr := linter.Check(pathString)
+			if checkErr != nil {
+				errorLogger.Error(checkErr)
+			}
+			for _, d := range result.Diagnostics {
+				if d.Kind == lint.Invalid {
+					diagnostics = append(diagnostics, lsp.Diagnostic{Path: files.NewPath(d.Path), Rule: d.Rule, Message: d.Message, Fix: d.Fix})
+				}
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"snekcheck/internal/files"
+	"snekcheck/internal/lsp"
+	"snekcheck/lint"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLspCheck(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for path, contents := range map[string]string{
+		".snekcheckignore": "Keep*\n",
+		"sub/KeepMe":       "",
+		"sub/Bad.go":       "",
+	} {
+		require.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.Nil(t, os.WriteFile(filepath.Join(dir, path), []byte(contents), 0o644))
+	}
+	fileSystem := osfs.New("/")
+	linter, newErr := lint.New(lint.Options{FileSystem: fileSystem, Dir: dir})
+	require.Nil(t, newErr)
+	var logged bytes.Buffer
+	check := lspCheck(fileSystem, linter, log.New(&logged))
+
+	t.Run("produces the diagnostics of created paths, with their rules and fixes", func(t *testing.T) {
+		path := files.NewPath(filepath.Join(dir, "sub", "Bad.go"))
+		assert.Equal(t, []lsp.Diagnostic{
+			{Path: path, Rule: "case", Message: `"Bad" is neither snake_case nor SCREAMING_SNAKE_CASE`, Fix: "bad.go"},
+		}, check([]files.Path{path}))
+	})
+	t.Run("skips created paths ignored by the directories enclosing them", func(t *testing.T) {
+		assert.Empty(t, check([]files.Path{files.NewPath(filepath.Join(dir, "sub", "KeepMe"))}))
+	})
+	t.Run("skips paths that no longer exist", func(t *testing.T) {
+		assert.Empty(t, check([]files.Path{files.NewPath(filepath.Join(dir, "Missing"))}))
+	})
+	assert.Empty(t, logged.String())
+}
@@ -4,8 +4,71 @@
 Usage:
 
 	snekcheck <flag> ... <path> ...
+	snekcheck <subcommand> <flag> ... <arg> ...
 
 If the `--fix` flag is specified, `snekcheck` will attempt to correct invalid filenames.
+
+Names are checked by built-in rules, which are reported with each violation: "posix", "case" and "extension".
+Rules may be disabled in the `rules` section of the configuration file, such as `extension: false`.
+
+The `plugins` section of the configuration file launches external rule plugins, such as company-specific prefix rules,
+which check batches of paths sent as JSON lines over their standard input. See `cmd/prefix_plugin` for a reference plugin.
+Since plugins run arbitrary commands, they only run with the `--plugins` flag, and are otherwise skipped with a warning.
+Their diagnostics are reported alongside those of the built-in rules, and `--fix` adopts the filenames they suggest.
+
+Filenames mandated by ecosystems, such as `Makefile`, `Dockerfile` and `Cargo.toml`, are valid.
+Ecosystems may be disabled in the `conventions` section of the configuration file, such as `ruby: false`,
+but `--fix` never renames their filenames.
+
+Paths ignored by Git, or by `.snekcheckignore` files with gitignore syntax, are skipped.
+Entries of `.snekcheckignore` files may expire, such as `legacy/OldThing.java until 2027-03-01 owner:@payments`.
+Once expired, an entry no longer ignores any paths, and is itself reported as a failure.
+The `--ignore-file` flag loads additional files with gitignore syntax, such as `.dockerignore`,
+and the `--no-gitignore` flag disregards Git's ignore sources.
+Common dependency and build directories, such as `node_modules`, `.venv`, `__pycache__` and `target`, are excluded by default.
+They may be re-included with `.snekcheckignore` negations, or all at once with the `--no-default-excludes` flag.
+
+The repeatable `--include` and `--exclude` flags filter paths by doublestar glob patterns, relative to the working directory.
+When any include patterns are specified, only matching paths are checked, such as `--include 'src/**'`.
+Paths matching exclude patterns are skipped entirely, such as `--exclude 'testdata/**'`.
+
+Files containing `snekcheck:ignore-name` in a comment within their first five lines are skipped,
+such as `<!-- snekcheck:ignore-name -->` in an `AndroidManifest.xml` file.
+
+Paths marked `linguist-generated` or `linguist-vendored` in `.gitattributes` only produce warnings,
+and paths marked `-snekcheck` or `snekcheck=false` are skipped.
+
+Symbolic links are checked, and renamed by `--fix`, as links rather than as their targets,
+though paths given as arguments that are symbolic links to directories are checked within, as with `find -H`.
+Dangling symbolic links are reported. The `--follow-symlinks` flag descends into symbolic links to directories,
+visiting each directory at most once, so that cycles are broken.
+
+Directories are read concurrently, by up to `--jobs` workers, though output is always sorted.
+Between runs, the entries of each directory and the verdicts of their names are cached in the user's cache directory,
+so that unmodified directories are neither read nor validated again. Ignore files are always evaluated afresh,
+and changes to the configuration invalidate the cache. The `--no-cache` flag disables the cache.
+
+The `--submodules` flag overrides how Git submodules are handled:
+either "recurse" into them using their own ignore rules and configuration, or "skip" them entirely.
+
+Invalid paths recorded in a `.snekcheck_baseline` file in the working directory are accepted as existing violations,
+so only new violations fail. Recorded paths that no longer exist are reported as stale.
+
+The `ratchet` section of the configuration file budgets the number of violations allowed within directories,
+such as `legacy/payments: 40`. Only budgets that are exceeded fail, and violations count against the most specific budget.
+Violations within budgets that are not exceeded are reported as budgeted.
+The `--update-ratchet` flag lowers budgets in the configuration file when the number of violations goes down.
+Only the budgets of directories within the checked paths are counted, and none are when paths are filtered by `--include` or `--exclude`.
+
+Subcommands:
+
+	baseline write  Records the current invalid paths in the baseline file.
+	history         Reports when the invalid names in a Git repository's history were introduced and fixed.
+	lsp             Serves diagnostics for invalid names over the Language Server Protocol.
+	refs            Validates a Git repository's branch and tag names.
+	watch           Checks paths again as entries are created or renamed within them, until interrupted.
+
+Configuration is read from a `.snekcheck.yaml` file.
 */
 package main
 
@@ -13,13 +76,19 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"snekcheck/internal/baseline"
+	"snekcheck/internal/cache"
 	"snekcheck/internal/files"
+	"snekcheck/lint"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
 )
 
 var (
@@ -31,12 +100,61 @@ var (
 // CLI flags.
 // TODO: Use a better flag library.
 var (
-	fix = flag.Bool("fix", false, "Whether snekcheck should attempt to correct invalid filenames")
+	fix               = flag.Bool("fix", false, "Whether snekcheck should attempt to correct invalid filenames")
+	include           []string
+	exclude           []string
+	ignoreFiles       []string
+	noGitIgnore       = flag.Bool("no-gitignore", false, "Whether snekcheck should disregard Git's ignore sources")
+	noDefaultExcludes = flag.Bool("no-default-excludes", false, "Whether snekcheck should check common dependency and build directories")
+	jobs              = flag.Int("jobs", 0, "The maximum number of directories snekcheck should read concurrently. Defaults to the number of CPUs")
+	followSymlinks    = flag.Bool("follow-symlinks", false, "Whether snekcheck should descend into symbolic links to directories")
+	updateRatchet     = flag.Bool("update-ratchet", false, "Whether snekcheck should lower ratchet budgets to the current violation counts")
+	noCache           = flag.Bool("no-cache", false, "Whether snekcheck should neither read nor write its cache")
+	submodules        = flag.String("submodules", "", "How snekcheck should handle Git submodules, either \"recurse\" or \"skip\"")
+	plugins           = flag.Bool("plugins", false, "Whether snekcheck should run the plugins of the configuration file")
 )
 
+func init() {
+	flag.Func("include", "A doublestar glob pattern of paths snekcheck should check exclusively. May be repeated", func(pattern string) error {
+		if !files.ValidGlob(pattern) {
+			return fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+		include = append(include, pattern)
+		return nil
+	})
+	flag.Func("exclude", "A doublestar glob pattern of paths snekcheck should skip. May be repeated", func(pattern string) error {
+		if !files.ValidGlob(pattern) {
+			return fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+		exclude = append(exclude, pattern)
+		return nil
+	})
+	flag.Func("ignore-file", "A file with gitignore syntax whose patterns snekcheck should ignore. May be repeated", func(path string) error {
+		ignoreFiles = append(ignoreFiles, path)
+		return nil
+	})
+}
+
+// Subcommands, keyed by name.
+// Each subcommand receives the CLI args following its name and produces an exit code.
+var subcommands = map[string]func(args []string) uint8{
+	"baseline": baselineSubcommand,
+	"history":  history,
+	"lsp":      lspSubcommand,
+	"refs":     refs,
+	"watch":    watch,
+}
+
 // The snekcheck CLI.
 // Will exit with a non-zero exit code upon failure.
 func main() {
+	// Dispatch to a subcommand, if one is specified.
+	if len(os.Args) > 1 {
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			exit(subcommand(os.Args[2:]))
+		}
+	}
+
 	// Initialize filesystem.
 	rootFs := osfs.New("/")
 	pwd, pwdErr := os.Getwd()
@@ -46,51 +164,130 @@ func main() {
 
 	// Parse CLI flags and args.
 	flag.Parse()
+	options := parseArgs(rootFs, pwd, flag.Args())
 
-	paths, pathsErr := absPaths(rootFs, pwd, flag.Args())
-	if pathsErr != nil {
-		logger.Error(pathsErr)
+	// Run sneckcheck.
+	if *fix {
+		result, fixErr := newLinter(options).Fix(flag.Args()...)
+		report(result)
+		if fixErr != nil {
+			logger.Error(fixErr)
+			exit(1)
+		}
+		exit(0)
+	}
+
+	options.Baseline = baselinePaths(loadBaseline(rootFs, files.NewPath(pwd)))
+	if !*noCache {
+		if cachePath, pathErr := cache.DefaultPath(files.NewPath(pwd)); pathErr == nil {
+			options.CacheFile = strings.Join(cachePath, string(os.PathSeparator))
+		} else {
+			logger.Warn(pathErr)
+		}
+	}
+	linter := newLinter(options)
+	result := check(linter, flag.Args())
+	if *updateRatchet {
+		lowered, lowerErr := linter.LowerRatchet(result)
+		if lowerErr != nil {
+			logger.Error(lowerErr)
+			exit(1)
+		}
+		for _, b := range lowered {
+			logger.Print("", "LOWERED", b.Prefix, "count", b.Count, "budget", b.Budget)
+		}
+	} else {
+		for _, b := range result.Budgets {
+			if b.Lowerable() {
+				logger.Infof("the budget of %s can be lowered from %d to %d with --update-ratchet", b.Prefix, b.Budget, b.Count)
+			}
+		}
+	}
+	if result.Failed() {
 		exit(1)
 	}
-	if len(paths) == 0 {
+	exit(0)
+}
+
+// Produces the options of a linter for the working directory from the CLI flags.
+// The linter resolves the CLI args, and any ignore files, against the working directory.
+// Exits if no CLI args are specified.
+func parseArgs(fs billy.Filesystem, pwd string, args []string) lint.Options {
+	if len(args) == 0 {
 		logger.Error("no valid files or directories specified")
 		exit(1)
 	}
-
-	// Run sneckcheck.
-	if *fix {
-		Fix(rootFs, paths)
-		exit(0)
+	return lint.Options{
+		FileSystem:        fs,
+		Dir:               pwd,
+		Include:           include,
+		Exclude:           exclude,
+		IgnoreFiles:       ignoreFiles,
+		NoGitIgnore:       *noGitIgnore,
+		NoDefaultExcludes: *noDefaultExcludes,
+		FollowSymlinks:    *followSymlinks,
+		Jobs:              *jobs,
+		Submodules:        *submodules,
+		Plugins:           *plugins,
 	}
+}
 
-	_, invalidPaths := Check(rootFs, paths)
-	if len(invalidPaths) != 0 {
+// Constructs a linter.
+// Exits upon failure.
+func newLinter(options lint.Options) *lint.Linter {
+	linter, linterErr := lint.New(options)
+	if linterErr != nil {
+		logger.Error(linterErr)
 		exit(1)
 	}
-	exit(0)
+	return linter
 }
 
-// Converts potentially relative paths to separated, absolute paths.
-// Errors if any provided path does not exist.
-func absPaths(fs billy.Filesystem, pwd string, paths []string) (absPaths []files.Path, err error) {
-	if fs == nil {
-		panic("invalid filesystem")
+// Constructs a linter with the configuration file at the root of a Git repository's worktree, if any.
+// Repositories without a worktree are linted with the default configuration.
+// Exits upon failure.
+func repoLinter(r *git.Repository) *lint.Linter {
+	worktree, worktreeErr := r.Worktree()
+	if worktreeErr != nil {
+		return newLinter(lint.Options{FileSystem: memfs.New()})
 	}
+	return newLinter(lint.Options{FileSystem: osfs.New("/"), Dir: worktree.Filesystem.Root()})
+}
 
-	absPaths = make([]files.Path, len(paths))
-	for i, path := range paths {
-		absPath := path
-		if !strings.HasPrefix(path, "/") {
-			absPath = fs.Join(pwd, path)
-		}
-		_, statErr := fs.Stat(absPath)
-		if statErr != nil {
-			err = fmt.Errorf("no such file or directory: %s", path)
-			return
+// Checks paths with a linter, reporting the result.
+// Exits upon failure.
+func check(linter *lint.Linter, paths []string) lint.Result {
+	result, checkErr := linter.Check(paths...)
+	report(result)
+	if checkErr != nil {
+		logger.Error(checkErr)
+		exit(1)
+	}
+	return result
+}
+
+// Logs the diagnostics and warnings of a linter's result.
+func report(result lint.Result) {
+	for _, warning := range result.Warnings {
+		logger.Warn(warning)
+	}
+	for _, d := range result.Diagnostics {
+		switch {
+		case d.Budget != nil:
+			logger.Print("", string(d.Kind), d.Path, "count", d.Budget.Count, "budget", d.Budget.Budget)
+		case d.Suppression != nil:
+			logger.Print("", string(d.Kind), d.Path, "pattern", d.Suppression.Pattern,
+				"until", d.Suppression.Until.Format(time.DateOnly), "owner", d.Suppression.Owner)
+		case d.NewPath != "":
+			logger.Print("", string(d.Kind), d.Path, "to", d.NewPath)
+		case d.Fix != "":
+			logger.Print("", string(d.Kind), d.Path, "rule", d.Rule, "message", d.Message, "fix", d.Fix)
+		case d.Rule != "":
+			logger.Print("", string(d.Kind), d.Path, "rule", d.Rule, "message", d.Message)
+		default:
+			logger.Print("", string(d.Kind), d.Path)
 		}
-		absPaths[i] = files.NewPath(absPath)
 	}
-	return
 }
 
 // Configures the CLI logger.
@@ -103,6 +300,24 @@ func configureLogger() (logger *log.Logger) {
 	styles.Values["VALID"] = lipgloss.NewStyle()
 	styles.Keys["FIXED"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#dcdcaa"))
 	styles.Values["FIXED"] = lipgloss.NewStyle()
+	styles.Keys["RESOLVED"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#6a9955"))
+	styles.Values["RESOLVED"] = lipgloss.NewStyle()
+	styles.Keys["BASELINE"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#808080"))
+	styles.Values["BASELINE"] = lipgloss.NewStyle()
+	styles.Keys["BUDGETED"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#808080"))
+	styles.Values["BUDGETED"] = lipgloss.NewStyle()
+	styles.Keys["STALE"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#569cd6"))
+	styles.Values["STALE"] = lipgloss.NewStyle()
+	styles.Keys["EXCEEDED"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#f44747"))
+	styles.Values["EXCEEDED"] = lipgloss.NewStyle()
+	styles.Keys["LOWERED"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#dcdcaa"))
+	styles.Values["LOWERED"] = lipgloss.NewStyle()
+	styles.Keys["EXPIRED"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#f44747"))
+	styles.Values["EXPIRED"] = lipgloss.NewStyle()
+	styles.Keys["DANGLING"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ce9178"))
+	styles.Values["DANGLING"] = lipgloss.NewStyle()
+	styles.Keys["WARNING"] = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ce9178"))
+	styles.Values["WARNING"] = lipgloss.NewStyle()
 	logger.SetStyles(styles)
 	return
 }
@@ -116,22 +331,21 @@ func exit(code uint8) {
 	os.Exit(int(code))
 }
 
-// Parses gitignore patterns in a single directory
-func parseGitIgnorePatterns(fs billy.Filesystem, path files.Path) files.GitIgnore {
-	patterns, ignoreErr := files.ParseGitIgnore(fs, path)
-	if ignoreErr != nil {
-		patterns = nil
+// Produces the paths accepted by a baseline, as the baseline of a linter.
+func baselinePaths(b baseline.Baseline) (paths []string) {
+	for _, path := range b.Paths() {
+		paths = append(paths, strings.Join(path, string(os.PathSeparator)))
 	}
-	return patterns
+	return
 }
 
-// Parses the list of global gitignore patterns.
-// Produces an empty list of patterns upon failure.
-func loadGlobalGitIgnore(fs billy.Filesystem) files.GitIgnore {
-	globalIgnorePatterns, ignoreErr := files.GlobalGitIgnorePatterns(fs)
-	if ignoreErr != nil {
-		logger.Warn(ignoreErr)
-		globalIgnorePatterns = nil
+// Loads the baseline file in a directory.
+// Exits upon failure.
+func loadBaseline(fs billy.Filesystem, dir files.Path) baseline.Baseline {
+	b, baselineErr := baseline.Load(fs, dir)
+	if baselineErr != nil {
+		logger.Error(baselineErr)
+		exit(1)
 	}
-	return globalIgnorePatterns
+	return b
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"snekcheck/internal/repo"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// The refs subcommand.
+// Validates a Git repository's branch and tag names, using the configuration file at the root of its worktree.
+//
+// Usage:
+//
+//	snekcheck refs [<path>]
+func refs(args []string) uint8 {
+	flags := flag.NewFlagSet("refs", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	path := "."
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		path = flags.Arg(0)
+	default:
+		logger.Error("at most one repository path may be specified")
+		return 1
+	}
+
+	r, openErr := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if openErr != nil {
+		logger.Errorf("not a git repository: %s", path)
+		return 1
+	}
+
+	linter := repoLinter(r)
+	validRefs, invalidRefs, refsErr := repo.Refs(r, linter.AllowedRefs(), linter.IsValid)
+	if refsErr != nil {
+		logger.Error(refsErr)
+		return 1
+	}
+	for _, ref := range validRefs {
+		logger.Print("", "VALID", ref)
+	}
+	for _, ref := range invalidRefs {
+		logger.Print("", "INVALID", ref)
+	}
+	if len(invalidRefs) != 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"snekcheck/internal/files"
+	"snekcheck/lint"
+	"strings"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-git/go-billy/v5/osfs"
+)
+
+// The watch subcommand.
+// Checks paths, then watches them for created and renamed entries, checking each entry as it appears.
+// New violations are reported as they appear, and violations are reported as resolved once their paths are renamed or removed.
+// With the --fix flag, invalid entries are renamed as they appear instead.
+// Accepts the same flags as a normal run. Runs until interrupted, failing if any violations remain.
+//
+// Usage:
+//
+//	snekcheck watch <flag> ... <path> ...
+func watch(args []string) uint8 {
+	rootFs := osfs.New("/")
+	pwd, pwdErr := os.Getwd()
+	if pwdErr != nil {
+		panic("could not determine present working directory")
+	}
+
+	_ = flag.CommandLine.Parse(args)
+	options := parseArgs(rootFs, pwd, flag.Args())
+	options.Baseline = baselinePaths(loadBaseline(rootFs, files.NewPath(pwd)))
+	linter := newLinter(options)
+	// Fixed paths are checked at their new paths, which are absolute.
+	paths, rootsErr := linter.Roots(flag.Args()...)
+	if rootsErr != nil {
+		logger.Error(rootsErr)
+		return 1
+	}
+
+	fsWatcher, watcherErr := fsnotify.NewWatcher()
+	if watcherErr != nil {
+		logger.Errorf("failed to watch for changes: %v", watcherErr)
+		return 1
+	}
+	defer fsWatcher.Close()
+
+	w := watcher{
+		linter:     linter,
+		fix:        *fix,
+		watches:    fsWatcher,
+		violations: make(map[string]files.Path),
+	}
+	w.check(paths)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	for {
+		select {
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return w.exitCode()
+			}
+			w.handle(event)
+		case eventErr, ok := <-fsWatcher.Errors:
+			if !ok {
+				return w.exitCode()
+			}
+			logger.Warn(eventErr)
+		case <-interrupt:
+			return w.exitCode()
+		}
+	}
+}
+
+// Watches file trees, keeping track of their violations.
+type watcher struct {
+	linter *lint.Linter
+	// Whether invalid entries are renamed as they appear.
+	fix     bool
+	watches *fsnotify.Watcher
+	// The current violations, keyed by their absolute string.
+	violations map[string]files.Path
+}
+
+// Handles a single filesystem event.
+// Only created, renamed and removed entries are of interest, since the contents of files do not affect their names.
+func (w *watcher) handle(event fsnotify.Event) {
+	switch {
+	case event.Has(fsnotify.Create):
+		w.check([]string{event.Name})
+	case event.Has(fsnotify.Rename), event.Has(fsnotify.Remove):
+		w.forget(files.NewPath(event.Name))
+	}
+}
+
+// Checks file trees, watching every directory within them, and recording their violations.
+// Invalid entries are renamed first, if fixing, in which case renamed paths are checked at their new paths.
+// Failures are only logged, since entries may be removed as soon as they are created.
+func (w *watcher) check(paths []string) {
+	if w.fix {
+		result, fixErr := w.linter.Fix(paths...)
+		report(result)
+		if fixErr != nil {
+			logger.Warn(fixErr)
+		}
+		paths = slices.Clone(paths)
+		for _, d := range result.Diagnostics {
+			if i := slices.Index(paths, d.Path); d.Kind == lint.Fixed && i != -1 {
+				paths[i] = d.NewPath
+			}
+		}
+	}
+
+	// Directories are watched before they are checked, so that no entries created in the meantime are missed.
+	dirs, dirsErr := w.linter.Dirs(paths...)
+	if dirsErr != nil {
+		logger.Warn(dirsErr)
+		return
+	}
+	for _, dir := range dirs {
+		if watchErr := w.watches.Add(dir); watchErr != nil {
+			logger.Warnf("failed to watch %s: %v", dir, watchErr)
+		}
+	}
+
+	result, checkErr := w.linter.Check(paths...)
+	report(result)
+	if checkErr != nil {
+		logger.Warn(checkErr)
+	}
+	for _, path := range result.Paths(lint.Invalid) {
+		w.violations[path] = files.NewPath(path)
+	}
+}
+
+// Forgets a path that no longer exists, reporting the violations within it as resolved.
+func (w *watcher) forget(path files.Path) {
+	for _, watched := range w.watches.WatchList() {
+		if path.Contains(files.NewPath(watched)) {
+			_ = w.watches.Remove(watched)
+		}
+	}
+	for _, violation := range slices.SortedFunc(maps.Values(w.violations), slices.Compare[files.Path]) {
+		if path.Contains(violation) {
+			delete(w.violations, strings.Join(violation, string(os.PathSeparator)))
+			logger.Print("", "RESOLVED", violation)
+		}
+	}
+}
+
+// Produces the exit code of the subcommand, which fails if any violations remain.
+func (w *watcher) exitCode() uint8 {
+	if len(w.violations) != 0 {
+		return 1
+	}
+	return 0
+}
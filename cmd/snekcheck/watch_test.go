@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"snekcheck/internal/files"
+	"snekcheck/lint"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Constructs a watcher of a temporary directory with files, producing the watcher and the directory.
+func newTestWatcher(t *testing.T, fix bool, paths ...string) (*watcher, string) {
+	dir := t.TempDir()
+	for _, path := range paths {
+		require.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.Nil(t, os.WriteFile(filepath.Join(dir, path), nil, 0o644))
+	}
+	linter, newErr := lint.New(lint.Options{FileSystem: osfs.New("/"), Dir: dir})
+	require.Nil(t, newErr)
+	fsWatcher, watcherErr := fsnotify.NewWatcher()
+	require.Nil(t, watcherErr)
+	t.Cleanup(func() { fsWatcher.Close() })
+	return &watcher{linter: linter, fix: fix, watches: fsWatcher, violations: make(map[string]files.Path)}, dir
+}
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+	t.Run("records created violations, and resolves them once removed", func(t *testing.T) {
+		w, dir := newTestWatcher(t, false, "good.go")
+		w.check([]string{dir})
+		assert.Empty(t, w.violations)
+		assert.Equal(t, uint8(0), w.exitCode())
+
+		bad := filepath.Join(dir, "Bad Dir", "Bad.go")
+		require.Nil(t, os.MkdirAll(filepath.Dir(bad), 0o755))
+		require.Nil(t, os.WriteFile(bad, nil, 0o644))
+		w.handle(fsnotify.Event{Name: filepath.Dir(bad), Op: fsnotify.Create})
+		assert.ElementsMatch(t, []string{filepath.Dir(bad), bad}, violationPaths(w.violations))
+		assert.Contains(t, w.watches.WatchList(), filepath.Dir(bad))
+		assert.Equal(t, uint8(1), w.exitCode())
+
+		require.Nil(t, os.RemoveAll(filepath.Dir(bad)))
+		w.handle(fsnotify.Event{Name: filepath.Dir(bad), Op: fsnotify.Remove})
+		assert.Empty(t, w.violations)
+		assert.NotContains(t, w.watches.WatchList(), filepath.Dir(bad))
+		assert.Equal(t, uint8(0), w.exitCode())
+	})
+	t.Run("resolves the violations of renamed entries", func(t *testing.T) {
+		w, dir := newTestWatcher(t, false, "Bad.go")
+		w.check([]string{dir})
+		assert.Equal(t, []string{filepath.Join(dir, "Bad.go")}, violationPaths(w.violations))
+
+		w.handle(fsnotify.Event{Name: filepath.Join(dir, "Bad.go"), Op: fsnotify.Rename})
+		assert.Empty(t, w.violations)
+	})
+	t.Run("ignores written entries", func(t *testing.T) {
+		w, dir := newTestWatcher(t, false, "Bad.go")
+		w.handle(fsnotify.Event{Name: filepath.Join(dir, "Bad.go"), Op: fsnotify.Write})
+		assert.Empty(t, w.violations)
+	})
+	t.Run("skips entries ignored by the directories enclosing them", func(t *testing.T) {
+		w, dir := newTestWatcher(t, false, ".snekcheckignore", "sub/KeepMe")
+		require.Nil(t, os.WriteFile(filepath.Join(dir, ".snekcheckignore"), []byte("Keep*\n"), 0o644))
+		w.check([]string{dir})
+
+		w.handle(fsnotify.Event{Name: filepath.Join(dir, "sub", "KeepMe"), Op: fsnotify.Create})
+		assert.Empty(t, w.violations)
+	})
+	t.Run("watches and checks renamed paths at their new paths when fixing", func(t *testing.T) {
+		w, dir := newTestWatcher(t, true, "Bad Dir/Inner File")
+		w.check([]string{filepath.Join(dir, "Bad Dir")})
+
+		assert.Empty(t, w.violations)
+		assert.Equal(t, []string{filepath.Join(dir, "bad_dir")}, w.watches.WatchList())
+		_, statErr := os.Stat(filepath.Join(dir, "bad_dir", "inner_file"))
+		assert.Nil(t, statErr)
+	})
+}
+
+// Produces the paths of a watcher's violations.
+func violationPaths(violations map[string]files.Path) (paths []string) {
+	for path := range violations {
+		paths = append(paths, path)
+	}
+	return
+}
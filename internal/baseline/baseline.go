@@ -0,0 +1,163 @@
+// Package baseline records accepted violations, so that only new violations fail.
+package baseline
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"snekcheck/internal/files"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// The name of snekcheck's baseline file.
+const FileName = ".snekcheck_baseline"
+
+// The comment written at the top of every baseline file.
+const header = "# Invalid paths accepted by snekcheck, relative to this file.\n" +
+	"# Regenerate with `snekcheck baseline write`.\n"
+
+// A set of invalid paths that are accepted as existing violations.
+type Baseline struct {
+	// The directory containing the baseline file, which entries are relative to.
+	dir files.Path
+	// The accepted paths, keyed by their absolute string.
+	entries map[string]files.Path
+}
+
+// Constructs a new Baseline accepting the given paths.
+func New(dir files.Path, paths []files.Path) Baseline {
+	b := Baseline{dir: slices.Clone(dir), entries: make(map[string]files.Path, len(paths))}
+	for _, path := range paths {
+		b.add(slices.Clone(path))
+	}
+	return b
+}
+
+// Loads the baseline file in a directory.
+// Produces an empty baseline if the directory does not contain a baseline file.
+func Load(fileSystem billy.Filesystem, dir files.Path) (b Baseline, err error) {
+	b = New(dir, nil)
+	path := dir.Join(FileName)
+	f, openErr := fileSystem.Open(path.String())
+	if errors.Is(openErr, fs.ErrNotExist) {
+		return b, nil
+	}
+	if openErr != nil {
+		return Baseline{}, fmt.Errorf("failed to open %s: %w", path, openErr)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		b.add(b.resolve(line))
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return Baseline{}, fmt.Errorf("failed to read %s: %w", path, scanErr)
+	}
+	return b, nil
+}
+
+// Writes the baseline file, replacing any existing one.
+// Entries are sorted, so that the file is stable under version control.
+func (b Baseline) Write(fileSystem billy.Filesystem) error {
+	lines := make([]string, 0, len(b.entries))
+	for _, path := range b.entries {
+		lines = append(lines, b.relative(path))
+	}
+	slices.Sort(lines)
+
+	var contents strings.Builder
+	contents.WriteString(header)
+	for _, line := range lines {
+		contents.WriteString(line + "\n")
+	}
+
+	path := b.dir.Join(FileName)
+	if writeErr := util.WriteFile(fileSystem, path.String(), []byte(contents.String()), 0o644); writeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", path, writeErr)
+	}
+	return nil
+}
+
+// Produces a new Baseline in which the entries within the given roots are replaced by the given paths.
+// Entries outside of every root are kept, since their paths were not checked again.
+func (b Baseline) Replace(roots []files.Path, paths []files.Path) Baseline {
+	replaced := New(b.dir, paths)
+	for k, path := range b.entries {
+		if !slices.ContainsFunc(roots, func(root files.Path) bool { return root.Contains(path) }) {
+			replaced.entries[k] = path
+		}
+	}
+	return replaced
+}
+
+// Determines if a path is accepted by the baseline.
+func (b Baseline) Contains(path files.Path) bool {
+	_, ok := b.entries[key(path)]
+	return ok
+}
+
+// Produces the number of accepted paths.
+func (b Baseline) Len() int {
+	return len(b.entries)
+}
+
+// Produces the accepted paths, in sorted order.
+func (b Baseline) Paths() (paths []files.Path) {
+	for _, path := range b.entries {
+		paths = append(paths, path)
+	}
+	slices.SortFunc(paths, func(a, b files.Path) int {
+		return slices.Compare(a, b)
+	})
+	return
+}
+
+// Produces the accepted paths that no longer exist, in sorted order.
+func (b Baseline) Stale(fileSystem billy.Filesystem) (stalePaths []files.Path) {
+	for _, path := range b.entries {
+		if _, statErr := fileSystem.Lstat(path.String()); errors.Is(statErr, fs.ErrNotExist) {
+			stalePaths = append(stalePaths, path)
+		}
+	}
+	slices.SortFunc(stalePaths, func(a, b files.Path) int {
+		return slices.Compare(a, b)
+	})
+	return
+}
+
+// Accepts a path.
+func (b Baseline) add(path files.Path) {
+	b.entries[key(path)] = path
+}
+
+// Converts an entry of the baseline file to an absolute path.
+// Entries are separated by slashes, and are relative to the baseline's directory unless absolute.
+func (b Baseline) resolve(entry string) files.Path {
+	if strings.HasPrefix(entry, "/") {
+		return strings.Split(entry, "/")
+	}
+	return slices.Concat(b.dir, strings.Split(entry, "/"))
+}
+
+// Converts an absolute path to an entry of the baseline file.
+func (b Baseline) relative(path files.Path) string {
+	if len(path) > len(b.dir) && b.dir.Contains(path) {
+		return strings.Join(path[len(b.dir):], "/")
+	}
+	return strings.Join(path, "/")
+}
+
+// Produces the key of a path in the set of accepted paths.
+func key(path files.Path) string {
+	return strings.Join(path, "/")
+}
@@ -0,0 +1,86 @@
+package baseline_test
+
+import (
+	"snekcheck/internal/baseline"
+	"snekcheck/internal/files"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	t.Run("produces an empty baseline without a baseline file", func(t *testing.T) {
+		fs := memfs.New()
+		b, loadErr := baseline.Load(fs, files.NewPath("/repo"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, 0, b.Len())
+	})
+	t.Run("parses entries relative to the baseline file", func(t *testing.T) {
+		fs := memfs.New()
+		contents := "# comment\n\nsrc/Legacy.go\n/other/Abs\n"
+		require.Nil(t, util.WriteFile(fs, "/repo/"+baseline.FileName, []byte(contents), 0o644))
+
+		b, loadErr := baseline.Load(fs, files.NewPath("/repo"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, 2, b.Len())
+		assert.True(t, b.Contains(files.NewPath("/repo/src/Legacy.go")))
+		assert.True(t, b.Contains(files.NewPath("/other/Abs")))
+		assert.False(t, b.Contains(files.NewPath("/repo/src/New.go")))
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	t.Run("round trips sorted entries", func(t *testing.T) {
+		fs := memfs.New()
+		dir := files.NewPath("/repo")
+		b := baseline.New(dir, []files.Path{
+			files.NewPath("/repo/src/Legacy.go"),
+			files.NewPath("/repo/Docs"),
+			files.NewPath("/other/Abs"),
+		})
+		require.Nil(t, b.Write(fs))
+
+		contents, readErr := util.ReadFile(fs, "/repo/"+baseline.FileName)
+		require.Nil(t, readErr)
+		assert.Contains(t, string(contents), "/other/Abs\nDocs\nsrc/Legacy.go\n")
+
+		loaded, loadErr := baseline.Load(fs, dir)
+		require.Nil(t, loadErr)
+		assert.Equal(t, b, loaded)
+	})
+}
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+	t.Run("only replaces the entries within the roots", func(t *testing.T) {
+		dir := files.NewPath("/repo")
+		b := baseline.New(dir, []files.Path{
+			files.NewPath("/repo/legacy/BadOne"),
+			files.NewPath("/repo/other/Fixed"),
+		})
+
+		replaced := b.Replace([]files.Path{files.NewPath("/repo/other")}, []files.Path{files.NewPath("/repo/other/New")})
+		assert.Equal(t, []files.Path{files.NewPath("/repo/legacy/BadOne"), files.NewPath("/repo/other/New")}, replaced.Paths())
+		assert.Equal(t, 2, b.Len())
+	})
+}
+
+func TestStale(t *testing.T) {
+	t.Parallel()
+	t.Run("produces entries that no longer exist", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, "/repo/Exists", nil, 0o644))
+		b := baseline.New(files.NewPath("/repo"), []files.Path{
+			files.NewPath("/repo/Exists"),
+			files.NewPath("/repo/Removed"),
+			files.NewPath("/repo/Renamed"),
+		})
+
+		assert.Equal(t, []files.Path{files.NewPath("/repo/Removed"), files.NewPath("/repo/Renamed")}, b.Stale(fs))
+	})
+}
@@ -0,0 +1,254 @@
+// Package cache records the entries of directories and the verdicts of their names between runs,
+// so that unmodified directories are neither read nor validated again.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"snekcheck/internal/files"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// The version of the cache format and of the validation rules whose verdicts are cached.
+// Incrementing it invalidates every existing cache.
+const Version = 1
+
+// The window before the start of a run in which modified directories are not cached,
+// since further modifications within the same timestamp granularity would go unnoticed.
+const racyWindow = 2 * time.Second
+
+// An on-disk cache of directory entries and name verdicts.
+// Entries are cached before ignore rules are applied, so ignore files are always evaluated afresh.
+// A nil Cache caches nothing. Safe for concurrent use.
+type Cache struct {
+	// The key of the configuration the cache was produced with.
+	key string
+	// When the run started.
+	now time.Time
+
+	mu sync.Mutex
+	// The directories of the previous run, keyed by path.
+	dirs map[string]*dir
+	// The directories loaded or stored during this run, keyed by path. Only these are saved.
+	used map[string]*dir
+}
+
+// A cached directory.
+type dir struct {
+	ModTime time.Time
+	// The entries of the directory, sorted by name.
+	Entries []entry
+}
+
+// A cached directory entry.
+type entry struct {
+	Name    string
+	Mode    fs.FileMode
+	Size    int64
+	ModTime time.Time
+	Verdict verdict
+}
+
+// Whether the name of an entry is valid.
+type verdict uint8
+
+const (
+	unknown verdict = iota
+	valid
+	invalid
+)
+
+// The encoded contents of a cache file.
+type contents struct {
+	Key  string
+	Dirs map[string]*dir
+}
+
+// Produces the key of a configuration, from its encoded parts, such as the configuration itself and the contents of ignore files.
+func Key(parts ...[]byte) string {
+	hash := sha256.New()
+	_ = binary.Write(hash, binary.LittleEndian, uint64(Version))
+	for _, part := range parts {
+		_ = binary.Write(hash, binary.LittleEndian, uint64(len(part)))
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Produces the path of the cache file for a working directory, within the user's cache directory.
+func DefaultPath(workingDir files.Path) (files.Path, error) {
+	userCacheDir, cacheDirErr := os.UserCacheDir()
+	if cacheDirErr != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", cacheDirErr)
+	}
+	hash := sha256.Sum256([]byte(strings.Join(workingDir, "/")))
+	return files.NewPath(filepath.Join(userCacheDir, "snekcheck", hex.EncodeToString(hash[:8])+".gob")), nil
+}
+
+// Opens the cache file at a path, for a run starting now with a configuration key.
+// Produces an empty cache if the file does not exist, or was produced with a different configuration.
+// The cache is usable even upon failure, though it is empty.
+func Open(fileSystem billy.Filesystem, path files.Path, key string, now time.Time) (c *Cache, err error) {
+	c = &Cache{key: key, now: now, dirs: make(map[string]*dir), used: make(map[string]*dir)}
+	encoded, readErr := util.ReadFile(fileSystem, path.String())
+	if errors.Is(readErr, fs.ErrNotExist) {
+		return c, nil
+	}
+	if readErr != nil {
+		return c, fmt.Errorf("failed to read %s: %w", path, readErr)
+	}
+
+	var decoded contents
+	if decodeErr := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&decoded); decodeErr != nil {
+		return c, fmt.Errorf("invalid cache file %s: %w", path, decodeErr)
+	}
+	if decoded.Key == key && decoded.Dirs != nil {
+		c.dirs = decoded.Dirs
+	}
+	return c, nil
+}
+
+// Writes the cache file at a path, replacing any existing one.
+// Only the directories encountered during this run are kept.
+func (c *Cache) Save(fileSystem billy.Filesystem, path files.Path) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	var encoded bytes.Buffer
+	encodeErr := gob.NewEncoder(&encoded).Encode(contents{Key: c.key, Dirs: c.used})
+	c.mu.Unlock()
+	if encodeErr != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, encodeErr)
+	}
+
+	if mkdirErr := fileSystem.MkdirAll(path.Parent().String(), 0o755); mkdirErr != nil {
+		return fmt.Errorf("failed to create %s: %w", path.Parent(), mkdirErr)
+	}
+	if writeErr := util.WriteFile(fileSystem, path.String(), encoded.Bytes(), 0o644); writeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", path, writeErr)
+	}
+	return nil
+}
+
+// Produces the cached entries of a directory, if they were stored for the same modification time.
+func (c *Cache) Load(dirPath files.Path, modTime time.Time) (entries []fs.FileInfo, ok bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := pathKey(dirPath)
+	d, ok := c.dirs[k]
+	if !ok || !d.ModTime.Equal(modTime) {
+		return nil, false
+	}
+	c.used[k] = d
+	entries = make([]fs.FileInfo, len(d.Entries))
+	for i, e := range d.Entries {
+		entries[i] = fileInfo{e}
+	}
+	return entries, true
+}
+
+// Stores the entries of a directory, sorted by name.
+// Directories modified just before the run started are not stored.
+func (c *Cache) Store(dirPath files.Path, modTime time.Time, entries []fs.FileInfo) {
+	if c == nil || modTime.After(c.now.Add(-racyWindow)) {
+		return
+	}
+
+	d := &dir{ModTime: modTime, Entries: make([]entry, len(entries))}
+	for i, e := range entries {
+		d.Entries[i] = entry{Name: e.Name(), Mode: e.Mode(), Size: e.Size(), ModTime: e.ModTime()}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := pathKey(dirPath)
+	c.dirs[k] = d
+	c.used[k] = d
+}
+
+// Produces the cached verdict of whether a path's name is valid, if any.
+// Only the names of entries of directories loaded or stored during this run have verdicts.
+func (c *Cache) Valid(path files.Path) (isValid bool, ok bool) {
+	if c == nil {
+		return false, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(path)
+	if e == nil || e.Verdict == unknown {
+		return false, false
+	}
+	return e.Verdict == valid, true
+}
+
+// Records the verdict of whether a path's name is valid.
+// Does nothing unless the path is an entry of a directory loaded or stored during this run.
+func (c *Cache) SetValid(path files.Path, isValid bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.entry(path); e != nil {
+		e.Verdict = invalid
+		if isValid {
+			e.Verdict = valid
+		}
+	}
+}
+
+// Produces the cached entry of a path, if its directory was loaded or stored during this run.
+func (c *Cache) entry(path files.Path) *entry {
+	if len(path) == 0 {
+		return nil
+	}
+	d, ok := c.used[pathKey(path.Parent())]
+	if !ok {
+		return nil
+	}
+	i, found := slices.BinarySearchFunc(d.Entries, path.Base(), func(e entry, name string) int {
+		return strings.Compare(e.Name, name)
+	})
+	if !found {
+		return nil
+	}
+	return &d.Entries[i]
+}
+
+// Produces the key of a directory in the cache.
+func pathKey(path files.Path) string {
+	return strings.Join(path, "/")
+}
+
+// The file info of a cached entry.
+type fileInfo struct {
+	e entry
+}
+
+func (f fileInfo) Name() string       { return f.e.Name }
+func (f fileInfo) Size() int64        { return f.e.Size }
+func (f fileInfo) Mode() fs.FileMode  { return f.e.Mode }
+func (f fileInfo) ModTime() time.Time { return f.e.ModTime }
+func (f fileInfo) IsDir() bool        { return f.e.Mode.IsDir() }
+func (f fileInfo) Sys() any           { return nil }
@@ -0,0 +1,150 @@
+package cache_test
+
+import (
+	"io/fs"
+	"snekcheck/internal/cache"
+	"snekcheck/internal/files"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	now     = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	modTime = now.Add(-time.Hour)
+	file    = files.NewPath("/cache/snekcheck/cache.gob")
+	dir     = files.NewPath("/repo/src")
+)
+
+// Reads the entries of a directory.
+func readDir(t *testing.T, contents map[string]string) []fs.FileInfo {
+	t.Helper()
+	fs := memfs.New()
+	for name, content := range contents {
+		require.Nil(t, util.WriteFile(fs, "/dir/"+name, []byte(content), 0o644))
+	}
+	entries, readErr := fs.ReadDir("/dir")
+	require.Nil(t, readErr)
+	return entries
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	t.Run("produces an empty cache without a cache file", func(t *testing.T) {
+		fs := memfs.New()
+		c, openErr := cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		_, ok := c.Load(dir, modTime)
+		assert.False(t, ok)
+	})
+	t.Run("round trips entries and verdicts", func(t *testing.T) {
+		fs := memfs.New()
+		entries := readDir(t, map[string]string{"Bad.go": "", "good.go": "package good"})
+		c, openErr := cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		c.Store(dir, modTime, entries)
+		c.SetValid(dir.Join("Bad.go"), false)
+		c.SetValid(dir.Join("good.go"), true)
+		require.Nil(t, c.Save(fs, file))
+
+		c, openErr = cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		loaded, ok := c.Load(dir, modTime)
+		require.True(t, ok)
+		require.Len(t, loaded, 2)
+		for i, entry := range loaded {
+			assert.Equal(t, entries[i].Name(), entry.Name())
+			assert.Equal(t, entries[i].Mode(), entry.Mode())
+			assert.Equal(t, entries[i].Size(), entry.Size())
+		}
+		valid, ok := c.Valid(dir.Join("Bad.go"))
+		assert.True(t, ok)
+		assert.False(t, valid)
+		valid, ok = c.Valid(dir.Join("good.go"))
+		assert.True(t, ok)
+		assert.True(t, valid)
+		_, ok = c.Valid(dir.Join("missing.go"))
+		assert.False(t, ok)
+	})
+	t.Run("does not load modified directories", func(t *testing.T) {
+		fs := memfs.New()
+		c, openErr := cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		c.Store(dir, modTime, readDir(t, map[string]string{"a": ""}))
+
+		_, ok := c.Load(dir, modTime.Add(time.Second))
+		assert.False(t, ok)
+	})
+	t.Run("does not store directories modified just before the run", func(t *testing.T) {
+		fs := memfs.New()
+		c, openErr := cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		c.Store(dir, now.Add(-time.Second), readDir(t, map[string]string{"a": ""}))
+
+		_, ok := c.Load(dir, now.Add(-time.Second))
+		assert.False(t, ok)
+	})
+	t.Run("is invalidated by a different key", func(t *testing.T) {
+		fs := memfs.New()
+		c, openErr := cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		c.Store(dir, modTime, readDir(t, map[string]string{"a": ""}))
+		require.Nil(t, c.Save(fs, file))
+
+		c, openErr = cache.Open(fs, file, "other", now)
+		require.Nil(t, openErr)
+		_, ok := c.Load(dir, modTime)
+		assert.False(t, ok)
+	})
+	t.Run("only saves directories encountered during the run", func(t *testing.T) {
+		fs := memfs.New()
+		other := files.NewPath("/repo/other")
+		c, openErr := cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		c.Store(dir, modTime, readDir(t, map[string]string{"a": ""}))
+		c.Store(other, modTime, readDir(t, map[string]string{"b": ""}))
+		require.Nil(t, c.Save(fs, file))
+
+		c, openErr = cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		_, ok := c.Load(dir, modTime)
+		require.True(t, ok)
+		require.Nil(t, c.Save(fs, file))
+
+		c, openErr = cache.Open(fs, file, "key", now)
+		require.Nil(t, openErr)
+		_, ok = c.Load(other, modTime)
+		assert.False(t, ok)
+	})
+	t.Run("produces an empty cache from an invalid cache file", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, file.String(), []byte("garbage"), 0o644))
+		c, openErr := cache.Open(fs, file, "key", now)
+		assert.NotNil(t, openErr)
+		require.NotNil(t, c)
+		_, ok := c.Load(dir, modTime)
+		assert.False(t, ok)
+	})
+	t.Run("caches nothing when nil", func(t *testing.T) {
+		var c *cache.Cache
+		c.Store(dir, modTime, readDir(t, map[string]string{"a": ""}))
+		_, ok := c.Load(dir, modTime)
+		assert.False(t, ok)
+		c.SetValid(dir.Join("a"), true)
+		_, ok = c.Valid(dir.Join("a"))
+		assert.False(t, ok)
+		assert.Nil(t, c.Save(memfs.New(), file))
+	})
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	t.Run("distinguishes how parts are split", func(t *testing.T) {
+		assert.Equal(t, cache.Key([]byte("ab"), []byte("c")), cache.Key([]byte("ab"), []byte("c")))
+		assert.NotEqual(t, cache.Key([]byte("ab"), []byte("c")), cache.Key([]byte("a"), []byte("bc")))
+	})
+}
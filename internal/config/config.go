@@ -0,0 +1,218 @@
+// Package config loads snekcheck's configuration file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"snekcheck/internal/conventions"
+	"snekcheck/internal/files"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"gopkg.in/yaml.v3"
+)
+
+// The name of snekcheck's configuration file.
+const FileName = ".snekcheck.yaml"
+
+// snekcheck's configuration.
+type Config struct {
+	// Doublestar glob patterns relative to the configuration file. When any are specified, only matching paths are validated.
+	Include []string `yaml:"include"`
+	// Doublestar glob patterns relative to the configuration file, whose matching paths are skipped entirely.
+	Exclude []string `yaml:"exclude"`
+	Ignore  Ignore   `yaml:"ignore"`
+	Refs    Refs     `yaml:"refs"`
+	// Ecosystems whose mandated filenames, such as Makefile, are accepted, keyed by name. Every ecosystem is enabled by default.
+	Conventions map[string]bool `yaml:"conventions"`
+	// Naming rules toggled by ID. Every rule is enabled by default.
+	Rules map[string]bool `yaml:"rules"`
+	// External rule plugins, which check every validated path.
+	Plugins []Plugin `yaml:"plugins"`
+	// The maximum allowed number of violations within directory prefixes relative to the configuration file.
+	Ratchet map[string]uint `yaml:"ratchet"`
+	// Whether symbolic links to directories are descended into.
+	FollowSymlinks bool `yaml:"follow_symlinks"`
+	// The maximum number of directories read concurrently. Defaults to the number of CPUs.
+	Jobs int `yaml:"jobs"`
+	// How Git submodules are handled. Defaults to SubmodulesRecurse.
+	Submodules string `yaml:"submodules"`
+}
+
+// The ways Git submodules can be handled.
+const (
+	// Walks submodules with their own ignore rules and configuration.
+	SubmodulesRecurse = "recurse"
+	// Excludes submodules entirely.
+	SubmodulesSkip = "skip"
+)
+
+// Configuration for which paths are ignored.
+type Ignore struct {
+	// Additional files with gitignore syntax, such as .dockerignore, relative to the configuration file.
+	// Their patterns are scoped to their own directory.
+	Files []string `yaml:"files"`
+	// Whether Git's ignore sources are disregarded, leaving only snekcheck's own.
+	NoGitIgnore bool `yaml:"no_gitignore"`
+	// Whether common dependency and build directories, such as node_modules, are no longer excluded.
+	NoDefaultExcludes bool `yaml:"no_default_excludes"`
+}
+
+// Configuration for validating Git branch and tag names.
+type Refs struct {
+	// Short reference name prefixes that are exempt from validation, such as "release/" or "v".
+	Allow []string `yaml:"allow"`
+}
+
+// An external rule plugin.
+type Plugin struct {
+	// The unique name of the plugin, which prefixes the IDs of its rules.
+	Name string `yaml:"name"`
+	// The executable and its arguments. Executable paths containing a separator are relative to the configuration file.
+	Command []string `yaml:"command"`
+	// The working directory of the plugin, namely the directory of the configuration file.
+	Dir string `yaml:"-"`
+}
+
+// Produces the configuration used when no configuration file exists.
+func Default() Config {
+	return Config{Submodules: SubmodulesRecurse}
+}
+
+// Loads the configuration file in a directory.
+// Produces the default configuration if the directory does not contain a configuration file.
+func Load(fileSystem billy.Filesystem, dir files.Path) (config Config, err error) {
+	path := dir.Join(FileName)
+	f, openErr := fileSystem.Open(path.String())
+	if errors.Is(openErr, fs.ErrNotExist) {
+		return Default(), nil
+	}
+	if openErr != nil {
+		return Config{}, fmt.Errorf("failed to open %s: %w", path, openErr)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	config = Default()
+	if decodeErr := decoder.Decode(&config); decodeErr != nil && !errors.Is(decodeErr, io.EOF) {
+		return Config{}, fmt.Errorf("invalid configuration file %s: %w", path, decodeErr)
+	}
+
+	for i, file := range config.Ignore.Files {
+		if !filepath.IsAbs(file) {
+			config.Ignore.Files[i] = filepath.Join(strings.Join(dir, string(filepath.Separator)), file)
+		}
+	}
+
+	for _, globs := range [][]string{config.Include, config.Exclude} {
+		for i, glob := range globs {
+			if !files.ValidGlob(glob) {
+				return Config{}, fmt.Errorf("invalid configuration file %s: invalid glob pattern %q", path, glob)
+			}
+			globs[i] = files.AbsGlob(dir, glob)
+		}
+	}
+
+	if config.Jobs < 0 {
+		return Config{}, fmt.Errorf("invalid configuration file %s: negative number of jobs %d", path, config.Jobs)
+	}
+
+	for ecosystem := range config.Conventions {
+		if !conventions.IsEcosystem(ecosystem) {
+			return Config{}, fmt.Errorf("invalid configuration file %s: unknown ecosystem %q", path, ecosystem)
+		}
+	}
+
+	names := make(map[string]bool, len(config.Plugins))
+	for i, plugin := range config.Plugins {
+		if plugin.Name == "" || strings.Contains(plugin.Name, "/") || names[plugin.Name] {
+			return Config{}, fmt.Errorf("invalid configuration file %s: invalid plugin name %q", path, plugin.Name)
+		}
+		names[plugin.Name] = true
+		if len(plugin.Command) == 0 {
+			return Config{}, fmt.Errorf("invalid configuration file %s: plugin %s has no command", path, plugin.Name)
+		}
+		config.Plugins[i].Dir = strings.Join(dir, string(filepath.Separator))
+		if executable := plugin.Command[0]; strings.ContainsRune(executable, filepath.Separator) && !filepath.IsAbs(executable) {
+			config.Plugins[i].Command[0] = filepath.Join(config.Plugins[i].Dir, executable)
+		}
+	}
+
+	for prefix := range config.Ratchet {
+		if filepath.IsAbs(prefix) {
+			return Config{}, fmt.Errorf("invalid configuration file %s: ratchet prefix %q is not relative", path, prefix)
+		}
+	}
+
+	switch config.Submodules {
+	case SubmodulesRecurse, SubmodulesSkip:
+	default:
+		return Config{}, fmt.Errorf("invalid configuration file %s: unknown submodules mode %q", path, config.Submodules)
+	}
+	return config, nil
+}
+
+// Updates the ratchet budgets of the configuration file in a directory, creating the file if necessary.
+// Other contents of the file, including comments, are preserved where possible.
+func SaveRatchet(fileSystem billy.Filesystem, dir files.Path, budgets map[string]uint) error {
+	path := dir.Join(FileName)
+	contents, readErr := util.ReadFile(fileSystem, path.String())
+	if readErr != nil && !errors.Is(readErr, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, readErr)
+	}
+
+	var document yaml.Node
+	if unmarshalErr := yaml.Unmarshal(contents, &document); unmarshalErr != nil {
+		return fmt.Errorf("invalid configuration file %s: %w", path, unmarshalErr)
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("invalid configuration file %s: not a mapping", path)
+	}
+
+	ratchet := mappingValue(root, "ratchet")
+	if ratchet.Kind != yaml.MappingNode {
+		*ratchet = yaml.Node{Kind: yaml.MappingNode}
+	}
+	prefixes := slices.Sorted(maps.Keys(budgets))
+	for _, prefix := range prefixes {
+		value := mappingValue(ratchet, prefix)
+		value.Kind, value.Tag, value.Style, value.Content = yaml.ScalarNode, "!!int", 0, nil
+		value.Value = strconv.FormatUint(uint64(budgets[prefix]), 10)
+	}
+
+	var encoded bytes.Buffer
+	encoder := yaml.NewEncoder(&encoded)
+	encoder.SetIndent(2)
+	if encodeErr := encoder.Encode(&document); encodeErr != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, encodeErr)
+	}
+	if writeErr := util.WriteFile(fileSystem, path.String(), encoded.Bytes(), 0o644); writeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", path, writeErr)
+	}
+	return nil
+}
+
+// Produces the value of a key in a YAML mapping, adding the key if necessary.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
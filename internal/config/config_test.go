@@ -0,0 +1,136 @@
+package config_test
+
+import (
+	"snekcheck/internal/config"
+	"snekcheck/internal/files"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	t.Run("produces the default configuration without a configuration file", func(t *testing.T) {
+		fs := memfs.New()
+		cfg, loadErr := config.Load(fs, files.NewPath("/"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, config.Default(), cfg)
+	})
+	t.Run("produces the default configuration for an empty configuration file", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, nil, 0o644))
+		cfg, loadErr := config.Load(fs, files.NewPath("/"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, config.Default(), cfg)
+	})
+	t.Run("parses a configuration file", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte("refs:\n  allow: [release/, v]\n"), 0o644))
+		cfg, loadErr := config.Load(fs, files.NewPath("/"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, []string{"release/", "v"}, cfg.Refs.Allow)
+	})
+	t.Run("resolves glob patterns relative to the configuration file", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, "repo/"+config.FileName, []byte("include: ['**/*.py']\nexclude: [/abs/**]\n"), 0o644))
+		cfg, loadErr := config.Load(fs, files.NewPath("/repo"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, []string{"/repo/**/*.py"}, cfg.Include)
+		assert.Equal(t, []string{"/abs/**"}, cfg.Exclude)
+	})
+	t.Run("errors on invalid glob patterns", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte("exclude: ['[']\n"), 0o644))
+		_, loadErr := config.Load(fs, files.NewPath("/"))
+		assert.NotNil(t, loadErr)
+	})
+	t.Run("errors on unknown ecosystems", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte("conventions:\n  cobol: false\n"), 0o644))
+		_, loadErr := config.Load(fs, files.NewPath("/"))
+		assert.NotNil(t, loadErr)
+	})
+	t.Run("errors on absolute ratchet prefixes", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte("ratchet:\n  /legacy: 1\n"), 0o644))
+		_, loadErr := config.Load(fs, files.NewPath("/"))
+		assert.NotNil(t, loadErr)
+	})
+	t.Run("resolves plugin executables relative to the configuration file", func(t *testing.T) {
+		fs := memfs.New()
+		contents := "plugins:\n  - name: local\n    command: [bin/plugin, arg]\n  - name: installed\n    command: [plugin]\n"
+		require.Nil(t, util.WriteFile(fs, "repo/"+config.FileName, []byte(contents), 0o644))
+		cfg, loadErr := config.Load(fs, files.NewPath("/repo"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, []config.Plugin{
+			{Name: "local", Command: []string{"/repo/bin/plugin", "arg"}, Dir: "/repo"},
+			{Name: "installed", Command: []string{"plugin"}, Dir: "/repo"},
+		}, cfg.Plugins)
+	})
+	t.Run("errors on invalid plugins", func(t *testing.T) {
+		testCases := map[string]string{
+			"missing name":   "plugins:\n  - command: [plugin]\n",
+			"duplicate name": "plugins:\n  - name: p\n    command: [plugin]\n  - name: p\n    command: [plugin]\n",
+			"no command":     "plugins:\n  - name: p\n",
+		}
+		for name, contents := range testCases {
+			t.Run(name, func(t *testing.T) {
+				fs := memfs.New()
+				require.Nil(t, util.WriteFile(fs, config.FileName, []byte(contents), 0o644))
+				_, loadErr := config.Load(fs, files.NewPath("/"))
+				assert.NotNil(t, loadErr)
+			})
+		}
+	})
+	t.Run("errors on unknown submodules modes", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte("submodules: ignore\n"), 0o644))
+		_, loadErr := config.Load(fs, files.NewPath("/"))
+		assert.NotNil(t, loadErr)
+	})
+	t.Run("errors on unknown fields", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte("refz: {}\n"), 0o644))
+		_, loadErr := config.Load(fs, files.NewPath("/"))
+		assert.NotNil(t, loadErr)
+	})
+	t.Run("does not write to the backing array of the directory", func(t *testing.T) {
+		fs := memfs.New()
+		siblings := files.Path{"", "repo", "sibling"}
+		dir := siblings[:2]
+		_, loadErr := config.Load(fs, dir)
+		require.Nil(t, loadErr)
+		assert.Equal(t, files.Path{"", "repo", "sibling"}, siblings)
+	})
+}
+
+func TestSaveRatchet(t *testing.T) {
+	t.Parallel()
+	t.Run("updates budgets while preserving other contents", func(t *testing.T) {
+		fs := memfs.New()
+		contents := "# budgets\nratchet:\n  legacy: 10 # payments\n  other: 3\nrefs:\n  allow: [v]\n"
+		require.Nil(t, util.WriteFile(fs, config.FileName, []byte(contents), 0o644))
+
+		require.Nil(t, config.SaveRatchet(fs, files.NewPath("/"), map[string]uint{"legacy": 4, "new": 0}))
+		saved, readErr := util.ReadFile(fs, config.FileName)
+		require.Nil(t, readErr)
+		assert.Contains(t, string(saved), "# budgets")
+		assert.Contains(t, string(saved), "legacy: 4 # payments")
+
+		cfg, loadErr := config.Load(fs, files.NewPath("/"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, map[string]uint{"legacy": 4, "other": 3, "new": 0}, cfg.Ratchet)
+		assert.Equal(t, []string{"v"}, cfg.Refs.Allow)
+	})
+	t.Run("creates a configuration file", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, config.SaveRatchet(fs, files.NewPath("/"), map[string]uint{"legacy": 4}))
+
+		cfg, loadErr := config.Load(fs, files.NewPath("/"))
+		require.Nil(t, loadErr)
+		assert.Equal(t, map[string]uint{"legacy": 4}, cfg.Ratchet)
+	})
+}
@@ -0,0 +1,70 @@
+// Package conventions lists the filenames mandated by ecosystems, which snekcheck accepts regardless of their case.
+package conventions
+
+import (
+	"path"
+	"slices"
+)
+
+// The version of the allowlist, incremented whenever names are added or removed.
+const Version = 1
+
+// A tool or language ecosystem, and the filenames it mandates.
+type Ecosystem struct {
+	// The name used to toggle the ecosystem.
+	Name string
+	// Filename patterns, in the syntax of path.Match.
+	Names []string
+}
+
+// Every ecosystem with mandated filenames, sorted by name.
+var Ecosystems = []Ecosystem{
+	{Name: "android", Names: []string{"AndroidManifest.xml", "gradle-wrapper.properties"}},
+	{Name: "apple", Names: []string{
+		"Info.plist", "Package.swift", "Podfile", "Podfile.lock", "Cartfile", "Cartfile.resolved",
+		"Fastfile", "Appfile", "Matchfile", "Gymfile", "Brewfile", "Brewfile.lock.json",
+	}},
+	{Name: "ci", Names: []string{"Jenkinsfile", "Jenkinsfile.*", ".gitlab-ci.yml", ".pre-commit-config.yaml", "Dangerfile"}},
+	{Name: "cmake", Names: []string{"CMakeLists.txt"}},
+	{Name: "docker", Names: []string{
+		"Dockerfile", "Dockerfile.*", "*.Dockerfile", "Containerfile", "Containerfile.*",
+		"docker-compose.yml", "docker-compose.yaml", "docker-compose.*.yml", "docker-compose.*.yaml",
+	}},
+	{Name: "dotnet", Names: []string{"Directory.Build.props", "Directory.Build.targets", "App.config", "Web.config", "NuGet.Config"}},
+	{Name: "go", Names: []string{"Gopkg.toml", "Gopkg.lock"}},
+	{Name: "haskell", Names: []string{"Setup.hs"}},
+	{Name: "javascript", Names: []string{"package-lock.json", "pnpm-lock.yaml", "pnpm-workspace.yaml", "Gruntfile.js", "Gulpfile.js", "next-env.d.ts"}},
+	{Name: "kubernetes", Names: []string{"Chart.yaml", "Chart.lock", "Kptfile", "Tiltfile", "Pulumi.yaml", "Pulumi.*.yaml"}},
+	{Name: "linux", Names: []string{"Kconfig", "Kbuild", "PKGBUILD", "APKBUILD", "Pkgfile"}},
+	{Name: "make", Names: []string{"Makefile", "GNUmakefile", "Makefile.am", "Makefile.in", "Justfile", "Taskfile.yml", "Earthfile", "SConstruct", "SConscript", "Doxyfile"}},
+	{Name: "python", Names: []string{"Pipfile", "Pipfile.lock", "requirements-*.txt", "Snakefile"}},
+	{Name: "ruby", Names: []string{"Gemfile", "Gemfile.lock", "Rakefile", "Guardfile", "Capfile", "Berksfile", "Steepfile", "Vagrantfile"}},
+	{Name: "rust", Names: []string{"Cargo.toml", "Cargo.lock"}},
+	{Name: "web", Names: []string{"Caddyfile", "Procfile"}},
+}
+
+// Determines if a name is a known ecosystem.
+func IsEcosystem(name string) bool {
+	return slices.ContainsFunc(Ecosystems, func(ecosystem Ecosystem) bool { return ecosystem.Name == name })
+}
+
+// The filenames of the enabled ecosystems.
+type Allowlist []Ecosystem
+
+// Constructs a new Allowlist of every ecosystem, except those toggled off.
+func NewAllowlist(toggles map[string]bool) Allowlist {
+	return slices.DeleteFunc(slices.Clone(Ecosystems), func(ecosystem Ecosystem) bool {
+		enabled, ok := toggles[ecosystem.Name]
+		return ok && !enabled
+	})
+}
+
+// Determines if a filename is mandated by any of the enabled ecosystems.
+func (a Allowlist) Contains(name string) bool {
+	return slices.ContainsFunc(a, func(ecosystem Ecosystem) bool {
+		return slices.ContainsFunc(ecosystem.Names, func(pattern string) bool {
+			matched, _ := path.Match(pattern, name)
+			return matched
+		})
+	})
+}
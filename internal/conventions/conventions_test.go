@@ -0,0 +1,50 @@
+package conventions_test
+
+import (
+	"path"
+	"slices"
+	"snekcheck/internal/conventions"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcosystems(t *testing.T) {
+	t.Parallel()
+	t.Run("are sorted by name", func(t *testing.T) {
+		assert.True(t, slices.IsSortedFunc(conventions.Ecosystems, func(a, b conventions.Ecosystem) int {
+			return strings.Compare(a.Name, b.Name)
+		}))
+	})
+	t.Run("have well-formed patterns", func(t *testing.T) {
+		for _, ecosystem := range conventions.Ecosystems {
+			for _, pattern := range ecosystem.Names {
+				_, matchErr := path.Match(pattern, "")
+				assert.Nil(t, matchErr, pattern)
+			}
+		}
+	})
+}
+
+func TestAllowlist(t *testing.T) {
+	t.Parallel()
+	t.Run("contains mandated names of every ecosystem by default", func(t *testing.T) {
+		allowlist := conventions.NewAllowlist(nil)
+		for _, name := range []string{"Makefile", "Dockerfile", "Dockerfile.dev", "Jenkinsfile", "Gemfile", "Cargo.toml", "AndroidManifest.xml"} {
+			assert.True(t, allowlist.Contains(name), name)
+		}
+		assert.False(t, allowlist.Contains("MyFile"))
+		assert.False(t, allowlist.Contains("makefile.Dockerfile.bak"))
+	})
+	t.Run("omits ecosystems toggled off", func(t *testing.T) {
+		allowlist := conventions.NewAllowlist(map[string]bool{"ruby": false, "rust": true})
+		assert.False(t, allowlist.Contains("Gemfile"))
+		assert.True(t, allowlist.Contains("Cargo.toml"))
+		assert.True(t, allowlist.Contains("Makefile"))
+	})
+	t.Run("determines known ecosystems", func(t *testing.T) {
+		assert.True(t, conventions.IsEcosystem("docker"))
+		assert.False(t, conventions.IsEcosystem("cobol"))
+	})
+}
@@ -0,0 +1,84 @@
+package files
+
+import (
+	"errors"
+	"os"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+)
+
+// Names of the gitattributes that affect snekcheck.
+const (
+	// Marks files whose names are produced by a generator.
+	attributeGenerated = "linguist-generated"
+	// Marks files whose names are chosen by a third party.
+	attributeVendored = "linguist-vendored"
+	// Unset or set to "false" to exempt files from snekcheck.
+	attributeSnekcheck = "snekcheck"
+)
+
+// The gitattributes that affect how snekcheck treats a path.
+type Attributes struct {
+	// Whether the path is marked linguist-generated.
+	Generated bool
+	// Whether the path is marked linguist-vendored.
+	Vendored bool
+	// Whether the path is exempt from snekcheck, marked -snekcheck or snekcheck=false.
+	Exempt bool
+}
+
+// Compiles a list of attribute patterns in order of increasing priority.
+// Produces a nil matcher, which matches nothing, for an empty list.
+func compileAttributes(patterns []gitattributes.MatchAttribute) gitattributes.Matcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	return gitattributes.NewMatcher(patterns)
+}
+
+// Determines the gitattributes of a path from a compiled matcher.
+func matchAttributes(matcher gitattributes.Matcher, path Path) (attributes Attributes) {
+	if matcher == nil {
+		return
+	}
+
+	// Attributes are matched one at a time, since the matcher only stops at the highest priority match
+	// once every requested attribute has been found.
+	isTrue := func(name string) bool {
+		matched, _ := matcher.Match(path, []string{name})
+		attribute, ok := matched[name]
+		return ok && (attribute.IsSet() || (attribute.IsValueSet() && attribute.Value() == "true"))
+	}
+	isFalse := func(name string) bool {
+		matched, _ := matcher.Match(path, []string{name})
+		attribute, ok := matched[name]
+		return ok && (attribute.IsUnset() || (attribute.IsValueSet() && attribute.Value() == "false"))
+	}
+
+	attributes.Generated = isTrue(attributeGenerated)
+	attributes.Vendored = isTrue(attributeVendored)
+	attributes.Exempt = isFalse(attributeSnekcheck)
+	return
+}
+
+// Parses the .gitattributes patterns in a directory.
+// Macro definitions are only allowed at the root of a repository.
+func ParseGitAttributes(fs billy.Filesystem, path Path, isRepositoryRoot bool) ([]gitattributes.MatchAttribute, error) {
+	return gitattributes.ReadAttributesFile(fs, path, ".gitattributes", isRepositoryRoot)
+}
+
+// Parses the info/attributes patterns of the repository rooted at a directory.
+// These take priority over every .gitattributes file in the repository.
+func ParseRepositoryAttributes(fs billy.Filesystem, root Path) ([]gitattributes.MatchAttribute, error) {
+	f, openErr := fs.Open(gitDir(fs, root).Join("info").Join("attributes").String())
+	if errors.Is(openErr, os.ErrNotExist) {
+		return nil, nil
+	}
+	if openErr != nil {
+		return nil, openErr
+	}
+
+	defer f.Close()
+	return gitattributes.ReadAttributes(f, root, true)
+}
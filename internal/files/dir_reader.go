@@ -0,0 +1,143 @@
+package files
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5"
+)
+
+// Reads directories with a bounded pool of workers, ahead of when their entries are needed.
+// The most recently requested directories are read first, matching the order of a depth-first traversal.
+type dirReader struct {
+	fs    billy.Filesystem
+	cache DirCache
+	// Whether directories are read concurrently at all.
+	concurrent bool
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// Requests that have not been started, with the next request at the top of the stack.
+	pending []*dirRead
+	// Whether requests are held back, so that a batch of requests is started in order.
+	paused bool
+	closed bool
+}
+
+// A request for the entries of a directory.
+type dirRead struct {
+	fs      billy.Filesystem
+	cache   DirCache
+	path    Path
+	done    chan struct{}
+	entries []fs.FileInfo
+	err     error
+}
+
+// Constructs a new dirReader with a number of workers.
+// Directories are read synchronously, only once their entries are needed, without multiple workers.
+func newDirReader(fileSystem billy.Filesystem, cache DirCache, jobs int) *dirReader {
+	r := &dirReader{fs: fileSystem, cache: cache, concurrent: jobs > 1}
+	r.cond = sync.NewCond(&r.mu)
+	if r.concurrent {
+		for range jobs {
+			go r.work()
+		}
+	}
+	return r
+}
+
+// Requests the entries of a directory.
+func (r *dirReader) request(path Path) *dirRead {
+	read := &dirRead{fs: r.fs, cache: r.cache, path: path}
+	if !r.concurrent {
+		return read
+	}
+
+	read.done = make(chan struct{})
+	r.mu.Lock()
+	r.pending = append(r.pending, read)
+	r.mu.Unlock()
+	r.cond.Signal()
+	return read
+}
+
+// Holds back requests until resumed.
+func (r *dirReader) pause() {
+	r.mu.Lock()
+	r.paused = true
+	r.mu.Unlock()
+}
+
+// Starts the requests made since pausing.
+func (r *dirReader) resume() {
+	r.mu.Lock()
+	r.paused = false
+	r.mu.Unlock()
+	r.cond.Broadcast()
+}
+
+// Stops the workers. Requests that have not been started are never completed.
+func (r *dirReader) close() {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.cond.Broadcast()
+}
+
+// Completes requests until closed.
+func (r *dirReader) work() {
+	for {
+		r.mu.Lock()
+		for !r.closed && (r.paused || len(r.pending) == 0) {
+			r.cond.Wait()
+		}
+		if r.closed {
+			r.mu.Unlock()
+			return
+		}
+		read := r.pending[len(r.pending)-1]
+		r.pending = r.pending[:len(r.pending)-1]
+		r.mu.Unlock()
+
+		read.entries, read.err = readDir(r.fs, r.cache, read.path)
+		close(read.done)
+	}
+}
+
+// Waits for the entries of a directory, reading them now if they were not requested ahead of time.
+func (d *dirRead) wait() ([]fs.FileInfo, error) {
+	if d.done == nil {
+		return readDir(d.fs, d.cache, d.path)
+	}
+	<-d.done
+	return d.entries, d.err
+}
+
+// Reads the entries of a directory, sorted by name.
+// Entries are loaded from the cache instead, if any, when the directory has not been modified since they were stored.
+func readDir(fileSystem billy.Filesystem, cache DirCache, path Path) ([]fs.FileInfo, error) {
+	var dirInfo fs.FileInfo
+	if cache != nil {
+		if info, statErr := fileSystem.Stat(path.String()); statErr == nil {
+			dirInfo = info
+			if entries, ok := cache.Load(path, dirInfo.ModTime()); ok {
+				return entries, nil
+			}
+		}
+	}
+
+	entries, readErr := fileSystem.ReadDir(path.String())
+	if readErr != nil {
+		return nil, readErr
+	}
+	slices.SortFunc(entries, func(a, b fs.FileInfo) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	if dirInfo != nil {
+		cache.Store(path, dirInfo.ModTime(), entries)
+	}
+	return entries, nil
+}
@@ -0,0 +1,39 @@
+package files
+
+import "github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+// Dependency, cache and build directories that are rarely worth checking, even when a repository does not ignore them.
+var defaultExcludes = parsePatterns(
+	// JavaScript
+	"node_modules/",
+	"bower_components/",
+	".next/",
+	".nuxt/",
+	".svelte-kit/",
+	".parcel-cache/",
+	// Python
+	".venv/",
+	"venv/",
+	"__pycache__/",
+	".mypy_cache/",
+	".pytest_cache/",
+	".ruff_cache/",
+	".tox/",
+	".nox/",
+	"*.egg-info/",
+	// Rust and Java
+	"target/",
+	".gradle/",
+	// Miscellaneous tools
+	".direnv/",
+	".terraform/",
+)
+
+// Parses a list of gitignore patterns that apply everywhere.
+func parsePatterns(patterns ...string) GitIgnore {
+	gitIgnore := make(GitIgnore, len(patterns))
+	for i, pattern := range patterns {
+		gitIgnore[i] = gitignore.ParsePattern(pattern, nil)
+	}
+	return gitIgnore
+}
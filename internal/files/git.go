@@ -2,12 +2,19 @@ package files
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5/config"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 )
 
@@ -15,13 +22,33 @@ import (
 type GitIgnore []gitignore.Pattern
 
 // Determines if a file path is ignored by Git.
+// Patterns matched against many paths should be compiled once instead.
 func (gi GitIgnore) Match(path Path, isDir bool) bool {
-	return gitignore.NewMatcher(gi).Match(path, isDir)
+	return gi.Compile().Match(path, isDir)
 }
 
+// Compiles the patterns, so that they may be matched against many paths without being rebuilt.
+func (gi GitIgnore) Compile() GitIgnoreMatcher {
+	if len(gi) == 0 {
+		return GitIgnoreMatcher{}
+	}
+	return GitIgnoreMatcher{matcher: gitignore.NewMatcher(gi)}
+}
+
+// A list of gitignore patterns compiled once. The zero value matches nothing.
+type GitIgnoreMatcher struct {
+	matcher gitignore.Matcher
+}
+
+// Determines if a file path is ignored by the compiled patterns.
+func (m GitIgnoreMatcher) Match(path Path, isDir bool) bool {
+	return m.matcher != nil && m.matcher.Match(path, isDir)
+}
+
+// Patterns that apply to every repository.
 var basePatterns = []gitignore.Pattern{gitignore.ParsePattern(".git/", nil)}
 
-// Parses the list of global gitignore patterns.
+// Parses the list of global gitignore patterns, from the system and user core.excludesFile settings.
 func GlobalGitIgnorePatterns(fs billy.Filesystem) ([]gitignore.Pattern, error) {
 	systemPatterns, systemErr := gitignore.LoadSystemPatterns(fs)
 	userPatterns, userErr := gitignore.LoadGlobalPatterns(fs)
@@ -30,41 +57,184 @@ func GlobalGitIgnorePatterns(fs billy.Filesystem) ([]gitignore.Pattern, error) {
 	if allErr != nil {
 		return nil, fmt.Errorf("failed to load gitignore patterns: %w", allErr)
 	}
-	return slices.Concat(basePatterns, systemPatterns, userPatterns), nil
+	return slices.Concat(systemPatterns, userPatterns), nil
 }
 
-// Parses the .git/info/exclude patterns in a directory.
+// Parses the .gitignore patterns in a directory.
 func ParseGitIgnore(fs billy.Filesystem, path Path) ([]gitignore.Pattern, error) {
-	ignorePatterns, parseErr := parseGitIgnoreFile(fs, append(path, ".gitignore"))
-	if parseErr != nil {
-		ignorePatterns = nil
+	return parseGitIgnoreFile(fs, path.Join(".gitignore"), path)
+}
+
+// The name of snekcheck's own ignore files, which use gitignore syntax.
+const snekcheckIgnoreFile = ".snekcheckignore"
+
+// Parses the .snekcheckignore patterns in a directory.
+// Time-boxed entries are only parsed as patterns until they expire, and expired entries are produced separately.
+func ParseSnekcheckIgnore(fs billy.Filesystem, path Path, now time.Time) (patterns []gitignore.Pattern, expired []Suppression, err error) {
+	file := path.Join(snekcheckIgnoreFile)
+	lines, readErr := readGitIgnoreFile(fs, file)
+	if readErr != nil {
+		return nil, nil, readErr
 	}
 
-	excludePatterns, parseErr := parseGitIgnoreFile(fs, append(path, ".git/info/exclude"))
-	if parseErr != nil {
-		excludePatterns = nil
+	for _, line := range lines {
+		suppression, ok := parseSuppression(file, line)
+		switch {
+		case !ok:
+			patterns = append(patterns, gitignore.ParsePattern(line, path))
+		case suppression.Expired(now):
+			expired = append(expired, suppression)
+		default:
+			patterns = append(patterns, gitignore.ParsePattern(suppression.Pattern, path))
+		}
 	}
+	return patterns, expired, nil
+}
+
+// Parses the patterns of an arbitrary file with gitignore syntax, such as a .dockerignore file.
+// The patterns are scoped to the file's directory.
+func ParseIgnoreFile(fs billy.Filesystem, path Path) ([]gitignore.Pattern, error) {
+	return parseGitIgnoreFile(fs, path, path.Parent())
+}
 
-	return append(ignorePatterns, excludePatterns...), nil
+// Determines if a directory is the root of a Git repository, containing a .git directory or file.
+func IsRepositoryRoot(fs billy.Filesystem, path Path) bool {
+	_, statErr := fs.Stat(path.Join(".git").String())
+	return statErr == nil
+}
+
+// Produces the directories from the root of the innermost repository containing a path down to the path's parent.
+// Produces nothing if the path is not within a repository.
+func RepositoryParents(fs billy.Filesystem, path Path) []Path {
+	// Prefixes are capped so that appending to them never overwrites the rest of the path.
+	for i := len(path) - 1; i > 0; i-- {
+		if IsRepositoryRoot(fs, path[:i:i]) {
+			parents := make([]Path, 0, len(path)-i)
+			for j := i; j < len(path); j++ {
+				parents = append(parents, path[:j:j])
+			}
+			return parents
+		}
+	}
+	return nil
+}
+
+// Parses the patterns that apply throughout the repository rooted at a directory,
+// from its core.excludesFile setting followed by its info/exclude file.
+// Reports whether the repository's own configuration sets core.excludesFile, overriding the global setting.
+func ParseRepositoryExcludes(fs billy.Filesystem, root Path) (patterns []gitignore.Pattern, overridesGlobal bool) {
+	dir := gitDir(fs, root)
+
+	if excludesFile := repositoryExcludesFile(fs, dir); excludesFile != "" {
+		overridesGlobal = true
+		if strings.HasPrefix(excludesFile, "~/") {
+			if home, homeErr := os.UserHomeDir(); homeErr == nil {
+				excludesFile = filepath.Join(home, excludesFile[2:])
+			}
+		}
+		if !filepath.IsAbs(excludesFile) {
+			excludesFile = fs.Join(root.String(), excludesFile)
+		}
+		excludesPatterns, parseErr := parseGitIgnoreFile(fs, NewPath(excludesFile), root)
+		if parseErr == nil {
+			patterns = append(patterns, excludesPatterns...)
+		}
+	}
+
+	excludePatterns, parseErr := parseGitIgnoreFile(fs, dir.Join("info").Join("exclude"), root)
+	if parseErr == nil {
+		patterns = append(patterns, excludePatterns...)
+	}
+	return patterns, overridesGlobal
 }
 
-// Parses the patterns from a given gitignore file.
-func parseGitIgnoreFile(fs billy.Filesystem, path Path) ([]gitignore.Pattern, error) {
+// Reads the core.excludesFile setting from the configuration in a Git directory.
+// Produces an empty string if it is not set.
+func repositoryExcludesFile(fs billy.Filesystem, gitDir Path) string {
+	contents, readErr := util.ReadFile(fs, gitDir.Join("config").String())
+	if readErr != nil {
+		return ""
+	}
+
+	raw := format.New()
+	if decodeErr := format.NewDecoder(bytes.NewReader(contents)).Decode(raw); decodeErr != nil {
+		return ""
+	}
+	return raw.Section("core").Options.Get("excludesfile")
+}
+
+// Parses the submodule paths declared by the .gitmodules file in a directory.
+func ParseGitModules(fs billy.Filesystem, path Path) ([]Path, error) {
+	contents, readErr := util.ReadFile(fs, path.Join(".gitmodules").String())
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	modules := config.NewModules()
+	if unmarshalErr := modules.Unmarshal(contents); unmarshalErr != nil {
+		return nil, fmt.Errorf("invalid .gitmodules file in %s: %w", path, unmarshalErr)
+	}
+
+	submodules := make([]Path, 0, len(modules.Submodules))
+	for _, submodule := range modules.Submodules {
+		if submodule.Validate() != nil {
+			continue
+		}
+		submodules = append(submodules, slices.Concat(path, strings.Split(submodule.Path, "/")))
+	}
+	return submodules, nil
+}
+
+// Locates the Git directory of a repository root.
+// Follows the "gitdir:" link in .git files, which are used by submodules and worktrees.
+func gitDir(fs billy.Filesystem, path Path) Path {
+	dotGit := path.Join(".git")
+	contents, readErr := util.ReadFile(fs, dotGit.String())
+	if readErr != nil || !bytes.HasPrefix(contents, []byte(gitDirPrefix)) {
+		return dotGit
+	}
+
+	target := strings.TrimSpace(string(contents[len(gitDirPrefix):]))
+	if filepath.IsAbs(target) {
+		return NewPath(target)
+	}
+	return NewPath(fs.Join(path.String(), target))
+}
+
+// The prefix of the link in a .git file.
+const gitDirPrefix = "gitdir:"
+
+// Parses the patterns from a given gitignore file, scoped to a domain directory.
+func parseGitIgnoreFile(fs billy.Filesystem, path Path, domain Path) ([]gitignore.Pattern, error) {
+	lines, readErr := readGitIgnoreFile(fs, path)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	patterns := make([]gitignore.Pattern, len(lines))
+	for i, line := range lines {
+		patterns[i] = gitignore.ParsePattern(line, domain)
+	}
+	return patterns, nil
+}
+
+// Reads the lines of a file with gitignore syntax, skipping comments and blank lines.
+func readGitIgnoreFile(fs billy.Filesystem, path Path) ([]string, error) {
 	f, openErr := fs.Open(path.String())
 	if openErr != nil {
 		return nil, openErr
 	}
 
 	defer f.Close()
-	var patterns []gitignore.Pattern
+	var lines []string
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		s := scanner.Text()
 		if !strings.HasPrefix(s, "#") && len(strings.TrimSpace(s)) > 0 {
-			patterns = append(patterns, gitignore.ParsePattern(s, path.Parent()))
+			lines = append(lines, s)
 		}
 	}
 
-	return patterns, nil
+	return lines, nil
 }
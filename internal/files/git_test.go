@@ -0,0 +1,153 @@
+package files_test
+
+import (
+	"snekcheck/internal/files"
+	"snekcheck/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitIgnore(t *testing.T) {
+	t.Parallel()
+	t.Run("parses .gitignore patterns", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.gitignore": "# comment\nignored\n",
+		})
+
+		patterns, parseErr := files.ParseGitIgnore(fs, files.NewPath("repo"))
+		require.Nil(t, parseErr)
+		gitIgnore := files.GitIgnore(patterns)
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/ignored"), false))
+		assert.False(t, gitIgnore.Match(files.NewPath("repo/other"), false))
+	})
+}
+
+func TestParseSnekcheckIgnore(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2027, time.March, 1, 12, 0, 0, 0, time.Local)
+	fs := testutil.InitFiles(t, map[string]string{
+		"repo/.snekcheckignore": "Plain\n" +
+			"legacy/OldThing.java until 2027-03-01 owner:@payments\n" +
+			"Expired until 2027-02-28\n" +
+			"Invalid until tomorrow\n",
+	})
+
+	patterns, expired, parseErr := files.ParseSnekcheckIgnore(fs, files.NewPath("repo"), now)
+	require.Nil(t, parseErr)
+	t.Run("parses plain and unexpired entries as patterns", func(t *testing.T) {
+		gitIgnore := files.GitIgnore(patterns)
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/Plain"), false))
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/legacy/OldThing.java"), false))
+		assert.False(t, gitIgnore.Match(files.NewPath("repo/Expired"), false))
+	})
+	t.Run("parses malformed time-boxed entries as plain patterns", func(t *testing.T) {
+		gitIgnore := files.GitIgnore(patterns)
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/Invalid until tomorrow"), false))
+	})
+	t.Run("produces expired entries", func(t *testing.T) {
+		assert.Equal(t, []files.Suppression{{
+			File:    files.NewPath("repo/.snekcheckignore"),
+			Pattern: "Expired",
+			Until:   time.Date(2027, time.February, 28, 0, 0, 0, 0, time.Local),
+		}}, expired)
+	})
+}
+
+func TestSuppressionExpired(t *testing.T) {
+	t.Parallel()
+	s := files.Suppression{Until: time.Date(2027, time.March, 1, 0, 0, 0, 0, time.Local)}
+	assert.False(t, s.Expired(time.Date(2027, time.March, 1, 23, 59, 0, 0, time.Local)))
+	assert.True(t, s.Expired(time.Date(2027, time.March, 2, 0, 0, 0, 0, time.Local)))
+}
+
+func TestParseRepositoryExcludes(t *testing.T) {
+	t.Parallel()
+	t.Run("parses info/exclude patterns", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/info/exclude": "excluded\n",
+		})
+
+		patterns, overridesGlobal := files.ParseRepositoryExcludes(fs, files.NewPath("repo"))
+		gitIgnore := files.GitIgnore(patterns)
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/excluded"), false))
+		assert.False(t, overridesGlobal)
+	})
+	t.Run("follows .git files to the exclude file of linked Git directories", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/sub/.git":                      "gitdir: ../.git/modules/sub\n",
+			"repo/.git/modules/sub/info/exclude": "excluded\n",
+		})
+
+		patterns, _ := files.ParseRepositoryExcludes(fs, files.NewPath("repo/sub"))
+		gitIgnore := files.GitIgnore(patterns)
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/sub/excluded"), false))
+		assert.False(t, gitIgnore.Match(files.NewPath("repo/excluded"), false))
+	})
+	t.Run("parses the core.excludesFile of the repository's configuration", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/config":       "[core]\n\texcludesFile = .excludes\n",
+			"repo/.excludes":         "*.tmp\n",
+			"repo/.git/info/exclude": "!keep.tmp\n",
+		})
+
+		patterns, overridesGlobal := files.ParseRepositoryExcludes(fs, files.NewPath("repo"))
+		gitIgnore := files.GitIgnore(patterns)
+		assert.True(t, overridesGlobal)
+		assert.True(t, gitIgnore.Match(files.NewPath("repo/a/file.tmp"), false))
+		assert.False(t, gitIgnore.Match(files.NewPath("repo/keep.tmp"), false))
+	})
+}
+
+func TestRepositoryParents(t *testing.T) {
+	t.Parallel()
+	t.Run("produces the directories from the innermost repository root to the parent", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"outer/.git/HEAD":          "",
+			"outer/inner/.git":         "gitdir: ../.git/modules/inner\n",
+			"outer/inner/a/b/file.txt": "",
+		})
+
+		parents := files.RepositoryParents(fs, files.NewPath("outer/inner/a/b/file.txt"))
+		assert.Equal(t, []files.Path{
+			files.NewPath("outer/inner"),
+			files.NewPath("outer/inner/a"),
+			files.NewPath("outer/inner/a/b"),
+		}, parents)
+	})
+	t.Run("produces nothing outside of a repository", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"dir/file.txt": "",
+		})
+
+		assert.Empty(t, files.RepositoryParents(fs, files.NewPath("dir/file.txt")))
+	})
+}
+
+func TestParseGitModules(t *testing.T) {
+	t.Parallel()
+	t.Run("parses submodule paths", func(t *testing.T) {
+		fs := memfs.New()
+		gitModules := `[submodule "lib"]
+	path = vendor/lib
+	url = https://example.com/lib.git
+[submodule "docs"]
+	path = docs
+	url = https://example.com/docs.git
+`
+		require.Nil(t, util.WriteFile(fs, "repo/.gitmodules", []byte(gitModules), 0o644))
+
+		submodules, parseErr := files.ParseGitModules(fs, files.NewPath("repo"))
+		require.Nil(t, parseErr)
+		assert.ElementsMatch(t, []files.Path{files.NewPath("repo/vendor/lib"), files.NewPath("repo/docs")}, submodules)
+	})
+	t.Run("errors without a .gitmodules file", func(t *testing.T) {
+		fs := memfs.New()
+		_, parseErr := files.ParseGitModules(fs, files.NewPath("repo"))
+		assert.NotNil(t, parseErr)
+	})
+}
@@ -0,0 +1,44 @@
+package files
+
+import (
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// The characters with special meaning in doublestar glob patterns.
+const globMetaCharacters = `*?[]{}\`
+
+// Determines if a doublestar glob pattern is well-formed.
+func ValidGlob(pattern string) bool {
+	return doublestar.ValidatePathPattern(pattern)
+}
+
+// Resolves a doublestar glob pattern relative to a directory, producing an absolute pattern.
+// Absolute patterns are left unchanged.
+func AbsGlob(dir Path, pattern string) string {
+	if strings.HasPrefix(pattern, pathSeparator) {
+		return pattern
+	}
+
+	var escaped strings.Builder
+	for _, r := range strings.Join(dir, pathSeparator) {
+		if strings.ContainsRune(globMetaCharacters, r) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return strings.TrimSuffix(escaped.String(), pathSeparator) + pathSeparator + pattern
+}
+
+// Determines if an absolute path matches any of a list of absolute doublestar glob patterns.
+// Malformed patterns never match.
+func MatchGlobs(patterns []string, path Path) bool {
+	name := strings.Join(path, pathSeparator)
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.PathMatch(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package files_test
+
+import (
+	"snekcheck/internal/files"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbsGlob(t *testing.T) {
+	t.Parallel()
+	t.Run("resolves relative patterns", func(t *testing.T) {
+		assert.Equal(t, "/repo/**/*.py", files.AbsGlob(files.NewPath("/repo"), "**/*.py"))
+		assert.Equal(t, "/*.py", files.AbsGlob(files.NewPath("/"), "*.py"))
+	})
+	t.Run("leaves absolute patterns unchanged", func(t *testing.T) {
+		assert.Equal(t, "/other/*.py", files.AbsGlob(files.NewPath("/repo"), "/other/*.py"))
+	})
+	t.Run("escapes special characters in the directory", func(t *testing.T) {
+		pattern := files.AbsGlob(files.NewPath("/[repo]"), "*.py")
+		assert.True(t, files.MatchGlobs([]string{pattern}, files.NewPath("/[repo]/main.py")))
+		assert.False(t, files.MatchGlobs([]string{pattern}, files.NewPath("/r/main.py")))
+	})
+}
+
+func TestMatchGlobs(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"/repo/**/*.py", "/repo/main.py", true},
+		{"/repo/**/*.py", "/repo/pkg/main.py", true},
+		{"/repo/**/*.py", "/repo/main.go", false},
+		{"/repo/testdata/**", "/repo/testdata", true},
+		{"/repo/testdata/**", "/repo/testdata/Invalid", true},
+		{"/repo/testdata/**", "/repo/pkg/testdata", false},
+		{"/repo/**/testdata", "/repo/pkg/testdata", true},
+		{"/repo/[", "/repo/[", false},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.matches, files.MatchGlobs([]string{testCase.pattern}, files.NewPath(testCase.path)), "%s %s", testCase.pattern, testCase.path)
+	}
+	t.Run("matches nothing without patterns", func(t *testing.T) {
+		assert.False(t, files.MatchGlobs(nil, files.NewPath("/repo")))
+	})
+}
@@ -0,0 +1,164 @@
+package files
+
+import (
+	"slices"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+)
+
+// Determines which paths are ignored, and their gitattributes, while walking a file tree.
+// Each directory's ignore and gitattributes patterns are scoped to that directory, so they never apply to its siblings.
+// Each repository root resets the scope, so patterns never apply across repository boundaries.
+//
+// Paths are ignored by Git's ignore sources, as well as by snekcheck's own .snekcheckignore files and default excludes.
+// The two are kept apart, so that negations in one never re-include paths ignored by the other.
+// Default excludes have the lowest priority, so .snekcheckignore negations may re-include them.
+// Time-boxed .snekcheckignore entries stop ignoring paths once they expire.
+type Ignore struct {
+	fs      billy.Filesystem
+	options IgnoreOptions
+	scopes  *ScopeStack[ignoreScope]
+}
+
+// Options for determining which paths are ignored.
+type IgnoreOptions struct {
+	// Gitignore patterns that apply to every repository, unless a repository's configuration overrides them.
+	Global GitIgnore
+	// Patterns that apply everywhere regardless of Git, such as those of arbitrary ignore files.
+	Extra GitIgnore
+	// Whether Git's ignore sources are disregarded, leaving only snekcheck's own.
+	NoGitIgnore bool
+	// Whether common dependency and build directories, such as node_modules, are no longer excluded.
+	NoDefaultExcludes bool
+	// The time at which time-boxed .snekcheckignore entries are considered expired. Defaults to the current time.
+	Now time.Time
+}
+
+// The patterns in effect within a directory.
+type ignoreScope struct {
+	gitIgnore       GitIgnore
+	snekcheckIgnore GitIgnore
+	// The .gitattributes patterns of the directory and its parents, in order of increasing priority.
+	attributes []gitattributes.MatchAttribute
+	// The info/attributes patterns of the enclosing repository, which take priority over every .gitattributes file.
+	repositoryAttributes []gitattributes.MatchAttribute
+	// The expired time-boxed entries of the directory's own .snekcheckignore file.
+	expired []Suppression
+
+	// The patterns above, compiled once when the scope is entered, and reused for every path within it.
+	gitIgnoreMatcher       GitIgnoreMatcher
+	snekcheckIgnoreMatcher GitIgnoreMatcher
+	attributesMatcher      gitattributes.Matcher
+}
+
+// Compiles the patterns of the scope.
+func (s ignoreScope) compile() ignoreScope {
+	s.gitIgnoreMatcher = s.gitIgnore.Compile()
+	s.snekcheckIgnoreMatcher = s.snekcheckIgnore.Compile()
+	s.attributesMatcher = compileAttributes(slices.Concat(s.attributes, s.repositoryAttributes))
+	return s
+}
+
+// Constructs a new Ignore.
+func NewIgnore(fs billy.Filesystem, options IgnoreOptions) *Ignore {
+	if fs == nil {
+		panic("invalid filesystem")
+	}
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+	i := &Ignore{fs: fs, options: options}
+	i.scopes = NewScopeStack(ignoreScope{gitIgnore: i.gitIgnoreBase(), snekcheckIgnore: i.snekcheckIgnoreBase()}.compile())
+	return i
+}
+
+// Enters a directory, scoping its ignore and gitattributes patterns to it and its descendants.
+// Patterns from enclosing directories in the same repository continue to apply, at a lower priority.
+// Reports whether the directory is the root of a repository.
+func (i *Ignore) Enter(dir Path) (isRepositoryRoot bool) {
+	inherited := i.scopes.Get(dir)
+	if isRepositoryRoot = IsRepositoryRoot(i.fs, dir); isRepositoryRoot {
+		inherited.gitIgnore = i.gitIgnoreBase()
+		if !i.options.NoGitIgnore {
+			excludes, overridesGlobal := ParseRepositoryExcludes(i.fs, dir)
+			if overridesGlobal {
+				inherited.gitIgnore = excludes
+			} else {
+				inherited.gitIgnore = slices.Concat(inherited.gitIgnore, excludes)
+			}
+		}
+		inherited.snekcheckIgnore = i.snekcheckIgnoreBase()
+
+		repositoryAttributes, parseErr := ParseRepositoryAttributes(i.fs, dir)
+		if parseErr != nil {
+			repositoryAttributes = nil
+		}
+		inherited.attributes = nil
+		inherited.repositoryAttributes = repositoryAttributes
+	}
+
+	var gitIgnorePatterns GitIgnore
+	if !i.options.NoGitIgnore {
+		patterns, parseErr := ParseGitIgnore(i.fs, dir)
+		if parseErr == nil {
+			gitIgnorePatterns = patterns
+		}
+	}
+	snekcheckIgnorePatterns, expired, parseErr := ParseSnekcheckIgnore(i.fs, dir, i.options.Now)
+	if parseErr != nil {
+		snekcheckIgnorePatterns, expired = nil, nil
+	}
+	attributes, parseErr := ParseGitAttributes(i.fs, dir, isRepositoryRoot)
+	if parseErr != nil {
+		attributes = nil
+	}
+
+	i.scopes.Push(dir, ignoreScope{
+		gitIgnore:            slices.Concat(inherited.gitIgnore, gitIgnorePatterns),
+		snekcheckIgnore:      slices.Concat(inherited.snekcheckIgnore, snekcheckIgnorePatterns),
+		attributes:           slices.Concat(inherited.attributes, attributes),
+		repositoryAttributes: inherited.repositoryAttributes,
+		expired:              expired,
+	}.compile())
+	return isRepositoryRoot
+}
+
+// Determines if a path is ignored by the patterns of the directories containing it.
+// The path's parent directory must have been entered.
+func (i *Ignore) Match(path Path, isDir bool) bool {
+	scope := i.scopes.Get(path)
+	return scope.snekcheckIgnoreMatcher.Match(path, isDir) || scope.gitIgnoreMatcher.Match(path, isDir)
+}
+
+// Determines the gitattributes of a path from the patterns of the directories containing it.
+// The path's parent directory must have been entered.
+func (i *Ignore) Attributes(path Path) Attributes {
+	scope := i.scopes.Get(path)
+	return matchAttributes(scope.attributesMatcher, path)
+}
+
+// Produces the expired time-boxed entries of an entered directory's own .snekcheckignore file.
+// Expired entries no longer ignore any paths.
+func (i *Ignore) Expired(dir Path) []Suppression {
+	return slices.DeleteFunc(slices.Clone(i.scopes.Get(dir).expired), func(s Suppression) bool {
+		return !slices.Equal(s.File.Parent(), dir)
+	})
+}
+
+// Produces the Git patterns that apply at the root of every repository.
+func (i *Ignore) gitIgnoreBase() GitIgnore {
+	if i.options.NoGitIgnore {
+		return nil
+	}
+	return i.options.Global
+}
+
+// Produces the snekcheck patterns that apply at the root of every repository.
+func (i *Ignore) snekcheckIgnoreBase() GitIgnore {
+	if i.options.NoDefaultExcludes {
+		return slices.Concat(basePatterns, i.options.Extra)
+	}
+	return slices.Concat(defaultExcludes, basePatterns, i.options.Extra)
+}
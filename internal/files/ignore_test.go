@@ -0,0 +1,355 @@
+package files_test
+
+import (
+	"fmt"
+	"snekcheck/internal/files"
+	"snekcheck/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Walks a file tree, producing every path that is not ignored.
+func walkIgnore(fs billy.Filesystem, ignore *files.Ignore, root string) []string {
+	match := func(path files.Path, isDir bool) bool { return !ignore.Match(path, isDir) }
+	var paths []string
+	for path, fileInfo := range files.IterTree(fs, match, files.NewPath(root), files.TreeOptions{}) {
+		if fileInfo.IsDir() {
+			ignore.Enter(path)
+		}
+		paths = append(paths, path.String())
+	}
+	return paths
+}
+
+func TestIgnore(t *testing.T) {
+	t.Parallel()
+	t.Run("applies patterns to descendants", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.gitignore":      "*.log\n",
+			"repo/a/debug.log":     "",
+			"repo/a/b/trace.log":   "",
+			"repo/a/b/main.go":     "",
+			"repo/a/b/c/error.log": "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.ElementsMatch(t, []string{"repo", "repo/.gitignore", "repo/a", "repo/a/b", "repo/a/b/main.go", "repo/a/b/c"}, paths)
+	})
+	t.Run("does not apply patterns to siblings", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/a/.gitignore": "ignored\n",
+			"repo/a/ignored":    "",
+			"repo/b/ignored":    "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.Contains(t, paths, "repo/b/ignored")
+		assert.NotContains(t, paths, "repo/a/ignored")
+	})
+	t.Run("negates patterns across nested levels", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.gitignore":              "*.txt\n",
+			"repo/drop.txt":                "",
+			"repo/a/.gitignore":            "!keep.txt\n",
+			"repo/a/keep.txt":              "",
+			"repo/a/drop.txt":              "",
+			"repo/a/b/keep.txt":            "",
+			"repo/a/b/c/.gitignore":        "keep.txt\n",
+			"repo/a/b/c/keep.txt":          "",
+			"repo/a/b/c/d/.gitignore":      "!keep.txt\n",
+			"repo/a/b/c/d/keep.txt":        "",
+			"repo/sibling/keep.txt":        "",
+			"repo/sibling/nested/keep.txt": "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.Contains(t, paths, "repo/a/keep.txt")
+		assert.Contains(t, paths, "repo/a/b/keep.txt")
+		assert.Contains(t, paths, "repo/a/b/c/d/keep.txt")
+		assert.NotContains(t, paths, "repo/drop.txt")
+		assert.NotContains(t, paths, "repo/a/drop.txt")
+		assert.NotContains(t, paths, "repo/a/b/c/keep.txt")
+		assert.NotContains(t, paths, "repo/sibling/keep.txt")
+		assert.NotContains(t, paths, "repo/sibling/nested/keep.txt")
+	})
+	t.Run("applies base patterns everywhere", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/a/.gitignore": "!*.bak\n",
+			"repo/a/b.bak":      "",
+			"repo/c.bak":        "",
+		})
+		base := files.GitIgnore{gitignore.ParsePattern("*.bak", nil)}
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{Global: base}), "repo")
+		assert.Contains(t, paths, "repo/a/b.bak")
+		assert.NotContains(t, paths, "repo/c.bak")
+	})
+	t.Run("isolates nested repositories from enclosing patterns", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/HEAD":             "",
+			"repo/.gitignore":            "*.txt\n",
+			"repo/sub/.git":              "gitdir: ../.git/modules/sub\n",
+			"repo/sub/notes.txt":         "",
+			"repo/vendor/lib/.git/HEAD":  "",
+			"repo/vendor/lib/readme.txt": "",
+			"repo/other/note.txt":        "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.Contains(t, paths, "repo/sub/notes.txt")
+		assert.Contains(t, paths, "repo/vendor/lib/readme.txt")
+		assert.NotContains(t, paths, "repo/other/note.txt")
+	})
+	t.Run("applies exclude files only at repository roots", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/info/exclude":   "excluded\n",
+			"repo/a/excluded":          "",
+			"repo/a/.git/info/exclude": "*\n",
+			"repo/a/.gitignore":        "",
+			"repo/b/nested/.git/HEAD":  "",
+			"repo/b/nested/excluded":   "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.NotContains(t, paths, "repo/a/excluded")
+		assert.Contains(t, paths, "repo/b/nested/excluded")
+	})
+	t.Run("replaces global patterns with the repository's core.excludesFile", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/config": "[core]\n\texcludesFile = .excludes\n",
+			"repo/.excludes":   "*.tmp\n",
+			"repo/file.tmp":    "",
+			"repo/file.bak":    "",
+			"other/file.bak":   "",
+		})
+		global := files.GitIgnore{gitignore.ParsePattern("*.bak", nil)}
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{Global: global}), "repo")
+		assert.NotContains(t, paths, "repo/file.tmp")
+		assert.Contains(t, paths, "repo/file.bak")
+		paths = walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{Global: global}), "other")
+		assert.NotContains(t, paths, "other/file.bak")
+	})
+	t.Run("never yields .git directories", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/HEAD": "",
+			"repo/file":      "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.ElementsMatch(t, []string{"repo", "repo/file"}, paths)
+	})
+	t.Run("applies .snekcheckignore patterns to descendants", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/fixtures/.snekcheckignore": "*.golden\n",
+			"repo/fixtures/A.golden":         "",
+			"repo/fixtures/nested/B.golden":  "",
+			"repo/other/C.golden":            "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.NotContains(t, paths, "repo/fixtures/A.golden")
+		assert.NotContains(t, paths, "repo/fixtures/nested/B.golden")
+		assert.Contains(t, paths, "repo/other/C.golden")
+	})
+	t.Run("does not re-include gitignored paths with .snekcheckignore negations", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.gitignore":       "*.log\n",
+			"repo/.snekcheckignore": "!*.log\n",
+			"repo/debug.log":        "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.NotContains(t, paths, "repo/debug.log")
+	})
+	t.Run("disregards Git's ignore sources", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/HEAD":         "",
+			"repo/.git/info/exclude": "excluded\n",
+			"repo/.gitignore":        "ignored\n",
+			"repo/.snekcheckignore":  "snekcheck_ignored\n",
+			"repo/excluded":          "",
+			"repo/ignored":           "",
+			"repo/global":            "",
+			"repo/snekcheck_ignored": "",
+		})
+		global := files.GitIgnore{gitignore.ParsePattern("global", nil)}
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{Global: global, NoGitIgnore: true}), "repo")
+		assert.ElementsMatch(t, []string{
+			"repo", "repo/.gitignore", "repo/.snekcheckignore", "repo/excluded", "repo/ignored", "repo/global",
+		}, paths)
+	})
+	t.Run("applies extra patterns everywhere", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.dockerignore":  "build\n",
+			"repo/build/main":     "",
+			"repo/nested/.git":    "gitdir: ../.git/modules/nested\n",
+			"repo/nested/build/a": "",
+			"other/build/main":    "",
+		})
+		extra, parseErr := files.ParseIgnoreFile(fs, files.NewPath("repo/.dockerignore"))
+		require.Nil(t, parseErr)
+
+		ignore := files.NewIgnore(fs, files.IgnoreOptions{Extra: extra})
+		paths := walkIgnore(fs, ignore, "repo")
+		assert.NotContains(t, paths, "repo/build")
+		assert.NotContains(t, paths, "repo/nested/build")
+		paths = walkIgnore(fs, ignore, "other")
+		assert.Contains(t, paths, "other/build")
+	})
+	t.Run("excludes dependency and build directories by default", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/node_modules/Pkg/index.js":     "",
+			"repo/app/__pycache__/Main.pyc":      "",
+			"repo/app/pkg.egg-info/PKG-INFO":     "",
+			"repo/target/Debug":                  "",
+			"repo/src/target":                    "",
+			"repo/.venv/lib/Python/site.py":      "",
+			"repo/nested/.git":                   "gitdir: ../.git/modules/nested\n",
+			"repo/nested/node_modules/A/main.js": "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.ElementsMatch(t, []string{
+			"repo", "repo/app", "repo/src", "repo/src/target", "repo/nested", "repo/nested/.git",
+		}, paths)
+	})
+	t.Run("re-includes default excludes with .snekcheckignore negations", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.snekcheckignore": "!target/\n",
+			"repo/target/main":      "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{}), "repo")
+		assert.Contains(t, paths, "repo/target/main")
+	})
+	t.Run("disregards default excludes", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/node_modules/index.js": "",
+		})
+
+		paths := walkIgnore(fs, files.NewIgnore(fs, files.IgnoreOptions{NoDefaultExcludes: true}), "repo")
+		assert.Contains(t, paths, "repo/node_modules/index.js")
+	})
+	t.Run("stops ignoring paths with expired time-boxed entries", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.snekcheckignore":   "Current until 2027-03-01\nExpired until 2027-02-28 owner:@payments\n",
+			"repo/Current":            "",
+			"repo/Expired":            "",
+			"repo/nested/placeholder": "",
+		})
+		now := time.Date(2027, time.March, 1, 0, 0, 0, 0, time.Local)
+
+		ignore := files.NewIgnore(fs, files.IgnoreOptions{Now: now})
+		paths := walkIgnore(fs, ignore, "repo")
+		assert.NotContains(t, paths, "repo/Current")
+		assert.Contains(t, paths, "repo/Expired")
+
+		expired := ignore.Expired(files.NewPath("repo"))
+		require.Len(t, expired, 1)
+		assert.Equal(t, "Expired", expired[0].Pattern)
+		assert.Equal(t, "@payments", expired[0].Owner)
+		assert.Empty(t, ignore.Expired(files.NewPath("repo/nested")))
+	})
+}
+
+func TestIgnoreAttributes(t *testing.T) {
+	// Walks a file tree, producing the attributes of every path.
+	walkAttributes := func(fs billy.Filesystem, root string) map[string]files.Attributes {
+		ignore := files.NewIgnore(fs, files.IgnoreOptions{})
+		match := func(path files.Path, isDir bool) bool { return !ignore.Match(path, isDir) }
+		attributes := make(map[string]files.Attributes)
+		for path, fileInfo := range files.IterTree(fs, match, files.NewPath(root), files.TreeOptions{}) {
+			attributes[path.String()] = ignore.Attributes(path)
+			if fileInfo.IsDir() {
+				ignore.Enter(path)
+			}
+		}
+		return attributes
+	}
+
+	t.Parallel()
+	t.Run("determines linguist attributes", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/HEAD":        "",
+			"repo/.gitattributes":   "*.pb.go linguist-generated\nthird_party/** linguist-vendored=true\n",
+			"repo/api.pb.go":        "",
+			"repo/main.go":          "",
+			"repo/third_party/A.js": "",
+		})
+
+		attributes := walkAttributes(fs, "repo")
+		assert.Equal(t, files.Attributes{Generated: true}, attributes["repo/api.pb.go"])
+		assert.Equal(t, files.Attributes{}, attributes["repo/main.go"])
+		assert.Equal(t, files.Attributes{Vendored: true}, attributes["repo/third_party/A.js"])
+	})
+	t.Run("determines exemptions", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.gitattributes": "Unset -snekcheck\nFalse snekcheck=false\nTrue snekcheck\n",
+			"repo/Unset":          "",
+			"repo/False":          "",
+			"repo/True":           "",
+		})
+
+		attributes := walkAttributes(fs, "repo")
+		assert.True(t, attributes["repo/Unset"].Exempt)
+		assert.True(t, attributes["repo/False"].Exempt)
+		assert.False(t, attributes["repo/True"].Exempt)
+	})
+	t.Run("prioritizes nested .gitattributes files", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.gitattributes":          "*.js linguist-generated\n",
+			"repo/a/.gitattributes":        "*.js -linguist-generated\n",
+			"repo/a/main.js":               "",
+			"repo/b/main.js":               "",
+			"repo/b/nested/.gitattributes": "",
+		})
+
+		attributes := walkAttributes(fs, "repo")
+		assert.False(t, attributes["repo/a/main.js"].Generated)
+		assert.True(t, attributes["repo/b/main.js"].Generated)
+	})
+	t.Run("prioritizes info/attributes over .gitattributes files", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"repo/.git/info/attributes": "*.js -linguist-generated\n",
+			"repo/a/.gitattributes":     "*.js linguist-generated\n",
+			"repo/a/main.js":            "",
+		})
+
+		attributes := walkAttributes(fs, "repo")
+		assert.False(t, attributes["repo/a/main.js"].Generated)
+	})
+}
+
+func BenchmarkIgnore(b *testing.B) {
+	// Ten thousand files, spread evenly across a growing number of directories with their own .gitignore file
+	const fileCount = 10000
+	for _, dirCount := range []int{1, 10, 100, 1000} {
+		fs := memfs.New()
+		for i := range dirCount {
+			dir := fmt.Sprintf("root/%d", i)
+			require.Nil(b, util.WriteFile(fs, dir+"/.gitignore", []byte("*.log\nbuild/\n!keep.log\n"), 0o644))
+			for j := range fileCount / dirCount {
+				require.Nil(b, util.WriteFile(fs, fmt.Sprintf("%s/%d.txt", dir, j), nil, 0o644))
+			}
+		}
+
+		b.Run(fmt.Sprintf("gitignores=%d", dirCount), func(b *testing.B) {
+			b.ReportAllocs()
+			var count int
+			for range b.N {
+				ignore := files.NewIgnore(fs, files.IgnoreOptions{})
+				count = len(walkIgnore(fs, ignore, "root"))
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*count), "ns/entry")
+		})
+	}
+}
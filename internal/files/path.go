@@ -4,6 +4,7 @@ package files
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -24,11 +25,29 @@ func (p Path) Base() string {
 }
 
 // Returns the every element of the path except the last. Will panic if the path is empty.
+// The parent's capacity is limited, so that appending to it never overwrites this path.
 func (p Path) Parent() Path {
-	return p[:len(p)-1]
+	return p[: len(p)-1 : len(p)-1]
+}
+
+// Produces a new path with an element appended, never sharing this path's backing array.
+func (p Path) Join(name string) Path {
+	path := make(Path, len(p)+1)
+	copy(path, p)
+	path[len(p)] = name
+	return path
+}
+
+// Determines if a path is equal to or a descendant of this path.
+func (p Path) Contains(path Path) bool {
+	return len(path) >= len(p) && slices.Equal(path[:len(p)], p)
 }
 
 // Converts a Path to a string by joining the elements with an OS-specific separator.
+// Absolute paths, whose first element is empty, keep their leading separator.
 func (p Path) String() string {
+	if len(p) > 1 && p[0] == "" {
+		return pathSeparator + filepath.Join(p[1:]...)
+	}
 	return filepath.Join(p...)
 }
@@ -0,0 +1,32 @@
+package files_test
+
+import (
+	"path/filepath"
+	"snekcheck/internal/files"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+	t.Run("joins without sharing backing arrays", func(t *testing.T) {
+		parent := make(files.Path, 2, 8)
+		copy(parent, files.NewPath("a/b"))
+		first := parent.Join("first")
+		second := parent.Join("second")
+		assert.Equal(t, files.NewPath("a/b/first"), first)
+		assert.Equal(t, files.NewPath("a/b/second"), second)
+	})
+	t.Run("appends to parents without overwriting the path", func(t *testing.T) {
+		path := files.NewPath("a/b/c")
+		sibling := append(path.Parent(), "d")
+		assert.Equal(t, files.NewPath("a/b/c"), path)
+		assert.Equal(t, files.NewPath("a/b/d"), sibling)
+	})
+	t.Run("converts to strings, keeping the leading separator of absolute paths", func(t *testing.T) {
+		assert.Equal(t, filepath.FromSlash("/a/b"), files.NewPath(filepath.FromSlash("/a/b")).String())
+		assert.Equal(t, filepath.FromSlash("a/b"), files.NewPath(filepath.FromSlash("a/b")).String())
+		assert.Equal(t, filepath.FromSlash("/"), files.NewPath(filepath.FromSlash("/")).String())
+	})
+}
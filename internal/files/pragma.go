@@ -0,0 +1,68 @@
+package files
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+)
+
+// The pragma that exempts a file's name from snekcheck, when it appears in a comment near the top of the file.
+const IgnoreNamePragma = "snekcheck:ignore-name"
+
+// Limits on how much of a file's header is read when searching for pragmas.
+const (
+	// The maximum number of lines read.
+	pragmaMaxLines = 5
+	// The maximum number of bytes read.
+	pragmaMaxBytes = 4096
+)
+
+// The line comment and block comment openers recognized as containing pragmas.
+// A comment only contains the pragma if the pragma immediately follows its opener, aside from whitespace.
+var commentPrefixes = []string{
+	// C-like languages, such as Java, Kotlin, Groovy and Go.
+	"//", "/*",
+	// Shells, Python, Ruby, YAML, TOML and Dockerfiles.
+	"#",
+	// SQL, Lua and Haskell.
+	"--", "{-",
+	// Lisps, assembly and INI files.
+	";",
+	// XML, HTML and Markdown.
+	"<!--",
+	// OCaml and Pascal.
+	"(*",
+	// Batch files.
+	"REM ", "rem ", "::",
+}
+
+// Determines if a file contains the ignore-name pragma in a comment within its first few lines.
+// Only a bounded header of regular files is read, so that special files such as FIFOs are never opened.
+// The file info describes the path itself, without following symbolic links. Unreadable files never contain the pragma.
+func HasIgnoreNamePragma(fileSystem billy.Filesystem, path Path, info fs.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	f, openErr := fileSystem.Open(path.String())
+	if openErr != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(f, pragmaMaxBytes))
+	for i := 0; i < pragmaMaxLines && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		hasPragma := slices.ContainsFunc(commentPrefixes, func(prefix string) bool {
+			comment, isComment := strings.CutPrefix(line, prefix)
+			return isComment && strings.HasPrefix(strings.TrimSpace(comment), IgnoreNamePragma)
+		})
+		if hasPragma {
+			return true
+		}
+	}
+	return false
+}
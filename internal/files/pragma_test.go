@@ -0,0 +1,51 @@
+package files_test
+
+import (
+	"snekcheck/internal/files"
+	"snekcheck/internal/testutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasIgnoreNamePragma(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]struct {
+		contents string
+		expected bool
+	}{
+		"Jenkinsfile":         {"// snekcheck:ignore-name\npipeline {}\n", true},
+		"AndroidManifest.xml": {"<?xml version=\"1.0\"?>\n<!-- snekcheck:ignore-name -->\n<manifest/>\n", true},
+		"Dockerfile":          {"#!/usr/bin/env docker\n# syntax=docker/dockerfile:1\n  # snekcheck:ignore-name\n", true},
+		"Block.java":          {"/* snekcheck:ignore-name */\n", true},
+		"Continued.java":      {"/**\n * snekcheck:ignore-name\n */\n", false},
+		"Heading.md":          {"# Why snekcheck:ignore-name is used\n", false},
+		"String.vim":          {"\"snekcheck:ignore-name\"\n", false},
+		"Bullet.md":           {"* snekcheck:ignore-name\n", false},
+		"Query.sql":           {"-- snekcheck:ignore-name\n", true},
+		"NotComment.txt":      {"snekcheck:ignore-name\n", false},
+		"TooLate.py":          {strings.Repeat("\n", 5) + "# snekcheck:ignore-name\n", false},
+		"TooFar.py":           {"#" + strings.Repeat(" ", 5000) + "snekcheck:ignore-name\n", false},
+		"Empty.py":            {"", false},
+	}
+	contents := make(map[string]string, len(testCases))
+	for name, testCase := range testCases {
+		contents["repo/"+name] = testCase.contents
+	}
+	fs := testutil.InitFiles(t, contents)
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			info, statErr := fs.Lstat("repo/" + name)
+			require.Nil(t, statErr)
+			assert.Equal(t, testCase.expected, files.HasIgnoreNamePragma(fs, files.NewPath("repo/"+name), info))
+		})
+	}
+	t.Run("does not contain the pragma when missing", func(t *testing.T) {
+		info, statErr := fs.Lstat("repo/Jenkinsfile")
+		require.Nil(t, statErr)
+		assert.False(t, files.HasIgnoreNamePragma(fs, files.NewPath("repo/Missing"), info))
+	})
+}
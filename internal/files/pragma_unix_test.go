@@ -0,0 +1,26 @@
+//go:build unix
+
+package files_test
+
+import (
+	"path/filepath"
+	"snekcheck/internal/files"
+	"syscall"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasIgnoreNamePragmaSpecialFiles(t *testing.T) {
+	t.Parallel()
+	t.Run("does not open FIFOs", func(t *testing.T) {
+		fifo := filepath.Join(t.TempDir(), "Pipe")
+		require.Nil(t, syscall.Mkfifo(fifo, 0o644))
+		fs := osfs.New("/")
+		info, statErr := fs.Lstat(fifo)
+		require.Nil(t, statErr)
+		assert.False(t, files.HasIgnoreNamePragma(fs, files.NewPath(fifo), info))
+	})
+}
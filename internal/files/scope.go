@@ -0,0 +1,39 @@
+package files
+
+import "slices"
+
+// A stack of values scoped to directories, such as the rules in effect while walking a file tree.
+// Scopes are popped once a walk leaves their directory, so walks must visit parent directories before their children.
+type ScopeStack[T any] struct {
+	scopes []scope[T]
+}
+
+// A value scoped to a directory and its descendants.
+type scope[T any] struct {
+	dir   Path
+	value T
+}
+
+// Constructs a new stack with a base value that applies to every path.
+func NewScopeStack[T any](base T) *ScopeStack[T] {
+	return &ScopeStack[T]{scopes: []scope[T]{{dir: nil, value: base}}}
+}
+
+// Pushes a value scoped to a directory, popping any scopes that do not contain the directory.
+func (s *ScopeStack[T]) Push(dir Path, value T) {
+	s.popTo(dir)
+	s.scopes = append(s.scopes, scope[T]{dir: slices.Clone(dir), value: value})
+}
+
+// Produces the value of the innermost scope containing a path, popping any scopes that do not contain the path.
+func (s *ScopeStack[T]) Get(path Path) T {
+	s.popTo(path)
+	return s.scopes[len(s.scopes)-1].value
+}
+
+// Pops every scope that does not contain a path. The base scope is never popped.
+func (s *ScopeStack[T]) popTo(path Path) {
+	for len(s.scopes) > 1 && !s.scopes[len(s.scopes)-1].dir.Contains(path) {
+		s.scopes = s.scopes[:len(s.scopes)-1]
+	}
+}
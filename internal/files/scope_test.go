@@ -0,0 +1,37 @@
+package files_test
+
+import (
+	"snekcheck/internal/files"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeStack(t *testing.T) {
+	t.Parallel()
+	t.Run("produces the base value outside of every scope", func(t *testing.T) {
+		stack := files.NewScopeStack("base")
+		assert.Equal(t, "base", stack.Get(files.NewPath("a/b")))
+	})
+	t.Run("produces the value of the innermost scope containing a path", func(t *testing.T) {
+		stack := files.NewScopeStack("base")
+		stack.Push(files.NewPath("a"), "a")
+		stack.Push(files.NewPath("a/b"), "b")
+		assert.Equal(t, "b", stack.Get(files.NewPath("a/b/c")))
+	})
+	t.Run("pops scopes that do not contain a path", func(t *testing.T) {
+		stack := files.NewScopeStack("base")
+		stack.Push(files.NewPath("a"), "a")
+		stack.Push(files.NewPath("a/b"), "b")
+		assert.Equal(t, "a", stack.Get(files.NewPath("a/c")))
+		assert.Equal(t, "base", stack.Get(files.NewPath("d")))
+		assert.Equal(t, "base", stack.Get(files.NewPath("a/b/c")))
+	})
+	t.Run("pops sibling scopes when pushing", func(t *testing.T) {
+		stack := files.NewScopeStack("base")
+		stack.Push(files.NewPath("a/b"), "b")
+		stack.Push(files.NewPath("a/c"), "c")
+		assert.Equal(t, "c", stack.Get(files.NewPath("a/c/d")))
+		assert.Equal(t, "base", stack.Get(files.NewPath("a/b/d")))
+	})
+}
@@ -0,0 +1,53 @@
+package files
+
+import (
+	"strings"
+	"time"
+)
+
+// The keyword separating the pattern of a time-boxed ignore entry from its expiry date.
+const untilKeyword = "until"
+
+// The prefix of a time-boxed ignore entry's owner.
+const ownerPrefix = "owner:"
+
+// A time-boxed .snekcheckignore entry, of the form `<pattern> until <YYYY-MM-DD> [owner:<owner>]`.
+// The entry only ignores paths until the end of its expiry date.
+type Suppression struct {
+	// The ignore file containing the entry.
+	File Path
+	// The gitignore pattern of the entry.
+	Pattern string
+	// The last day the entry ignores paths.
+	Until time.Time
+	// Who is responsible for the entry, if anyone.
+	Owner string
+}
+
+// Determines if the entry has stopped ignoring paths.
+func (s Suppression) Expired(now time.Time) bool {
+	return !now.Before(s.Until.AddDate(0, 0, 1))
+}
+
+// Parses a time-boxed ignore entry.
+// Reports whether the line is a time-boxed entry at all, rather than a plain pattern.
+func parseSuppression(file Path, line string) (s Suppression, ok bool) {
+	fields := strings.Fields(line)
+	i := len(fields) - 2
+	if i >= 1 && strings.HasPrefix(fields[len(fields)-1], ownerPrefix) {
+		s.Owner = strings.TrimPrefix(fields[len(fields)-1], ownerPrefix)
+		i--
+	}
+	if i < 1 || fields[i] != untilKeyword {
+		return Suppression{}, false
+	}
+
+	until, parseErr := time.ParseInLocation(time.DateOnly, fields[i+1], time.Local)
+	if parseErr != nil {
+		return Suppression{}, false
+	}
+	s.File = file
+	s.Pattern = strings.Join(fields[:i], " ")
+	s.Until = until
+	return s, true
+}
@@ -3,6 +3,10 @@ package files
 import (
 	"io/fs"
 	"iter"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-billy/v5"
 )
@@ -10,37 +14,151 @@ import (
 // A Matcher determines if a file path matches implementation-specific constraints or not.
 type Matcher func(path Path, isDir bool) bool
 
+// Options for iterating over a file tree.
+type TreeOptions struct {
+	// Whether symbolic links to directories are descended into.
+	// Each directory is only descended into once, so that symbolic link cycles are broken.
+	FollowSymlinks bool
+	// The maximum number of directories read concurrently, ahead of iteration.
+	// Directories are read one at a time, only once they are reached, unless greater than one.
+	Jobs int
+	// A cache of directory entries, so that unmodified directories are not read again. May be nil.
+	Cache DirCache
+}
+
+// A cache of the entries of directories, keyed by their path and modification time.
+// Must be safe for concurrent use.
+type DirCache interface {
+	// Produces the cached entries of a directory, sorted by name, if they were stored for the same modification time.
+	Load(dir Path, modTime time.Time) (entries []fs.FileInfo, ok bool)
+	// Stores the entries of a directory, sorted by name.
+	Store(dir Path, modTime time.Time, entries []fs.FileInfo)
+}
+
 // Iterates over a file tree, only producing paths that match the given matcher.
-// The iterator is guaranteed to yield parent directories before their children.
-func IterTree(fileSystem billy.Filesystem, match Matcher, p Path) iter.Seq2[Path, fs.FileInfo] {
+// The iterator is guaranteed to yield parent directories before their children, and to yield each directory's
+// descendants contiguously, sorted by name. The matcher is called for a directory's entries after it is yielded.
+// Every yielded path is a distinct copy, which may be retained.
+// Symbolic links are produced as links, describing the link itself rather than its target.
+// When following symbolic links, a link to a directory reports itself as a directory, and is descended into.
+// The starting path itself is resolved, as with find -H, so that a symbolic link to a directory is always descended into,
+// and a dangling symbolic link is produced as a link.
+func IterTree(fileSystem billy.Filesystem, match Matcher, p Path, options TreeOptions) iter.Seq2[Path, fs.FileInfo] {
 	return func(yield func(path Path, fileInfo fs.FileInfo) bool) {
-		// Process this path
-		fileInfo, statErr := fileSystem.Stat(p.String())
+		rootInfo, statErr := fileSystem.Stat(p.String())
 		if statErr != nil {
-			return
+			rootInfo, statErr = fileSystem.Lstat(p.String())
 		}
-
-		if !match(p, fileInfo.IsDir()) {
+		if statErr != nil {
 			return
 		}
 
-		if !yield(p, fileInfo) || !fileInfo.IsDir() {
-			return
+		reader := newDirReader(fileSystem, options.Cache, options.Jobs)
+		defer reader.close()
+		// Directories are only descended into once, keyed by their identity.
+		visited := make(map[any]bool)
+		// Paths waiting to be yielded, with the next path at the top of the stack.
+		var stack []treeEntry
+		// Pushes a path onto the stack if it matches, requesting its entries ahead of time if it is a directory.
+		push := func(path Path, fileInfo fs.FileInfo) {
+			entry := treeEntry{path: path}
+			entry.fileInfo, entry.key = resolveTreeEntry(fileSystem, path, fileInfo, options)
+			if !match(path, entry.fileInfo.IsDir()) {
+				return
+			}
+			if entry.fileInfo.IsDir() && !visited[entry.key] {
+				entry.entries = reader.request(path)
+			}
+			stack = append(stack, entry)
 		}
 
-		// Attempt to read directory entries
-		entries, readErr := fileSystem.ReadDir(p.String())
-		if readErr != nil {
-			entries = nil
-		}
+		push(slices.Clone(p), rootInfo)
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			// Process this path
+			if !yield(current.path, current.fileInfo) {
+				return
+			}
+			if current.entries == nil || visited[current.key] {
+				continue
+			}
+			visited[current.key] = true
 
-		// Process entries if it is a directory
-		for _, entry := range entries {
-			for entryPath, fileInfo := range IterTree(fileSystem, match, append(p, entry.Name())) {
-				if !yield(entryPath, fileInfo) {
-					return
-				}
+			// Attempt to read directory entries
+			entries, readErr := current.entries.wait()
+			if readErr != nil {
+				continue
 			}
+
+			// Push entries in reverse, so that they are processed in order
+			reader.pause()
+			for i := len(entries) - 1; i >= 0; i-- {
+				push(current.path.Join(entries[i].Name()), entries[i])
+			}
+			reader.resume()
 		}
 	}
 }
+
+// A path waiting to be yielded while iterating over a file tree.
+type treeEntry struct {
+	path     Path
+	fileInfo fs.FileInfo
+	// The key identifying a directory.
+	key any
+	// The pending entries of a directory that has not been visited.
+	entries *dirRead
+}
+
+// Determines the file info to yield for a path, following symbolic links to directories if requested.
+// Produces a key identifying directories, which is nil for other paths.
+// The given file info describes the path itself, without following symbolic links.
+func resolveTreeEntry(fileSystem billy.Filesystem, p Path, fileInfo fs.FileInfo, options TreeOptions) (_ fs.FileInfo, key any) {
+	if fileInfo.Mode()&fs.ModeSymlink != 0 {
+		if !options.FollowSymlinks {
+			return fileInfo, nil
+		}
+		if targetInfo, targetErr := fileSystem.Stat(p.String()); targetErr == nil && targetInfo.IsDir() {
+			return linkInfo{FileInfo: fileInfo, isDir: true}, fileKey(targetInfo, symlinkTarget(fileSystem, p))
+		}
+		return fileInfo, nil
+	}
+	if fileInfo.IsDir() {
+		key = fileKey(fileInfo, path.Clean(strings.Join(p, "/")))
+	}
+	return fileInfo, key
+}
+
+// The file info of a followed symbolic link, which describes the link itself but reports whether its target is a directory.
+type linkInfo struct {
+	fs.FileInfo
+	isDir bool
+}
+
+// Determines if the target of the link is a directory.
+func (l linkInfo) IsDir() bool {
+	return l.isDir
+}
+
+// Resolves the target of a symbolic link lexically, without resolving any further links.
+func symlinkTarget(fileSystem billy.Filesystem, p Path) string {
+	target, readErr := fileSystem.Readlink(p.String())
+	if readErr != nil {
+		return path.Clean(strings.Join(p, "/"))
+	}
+	if !strings.HasPrefix(target, "/") {
+		target = path.Join(strings.Join(p.Parent(), "/"), target)
+	}
+	return path.Clean(target)
+}
+
+// Determines if a path is a symbolic link whose target does not exist.
+func IsDanglingSymlink(fileSystem billy.Filesystem, p Path, fileInfo fs.FileInfo) bool {
+	if fileInfo.Mode()&fs.ModeSymlink == 0 {
+		return false
+	}
+	_, statErr := fileSystem.Stat(p.String())
+	return statErr != nil
+}
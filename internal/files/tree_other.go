@@ -0,0 +1,10 @@
+//go:build !unix
+
+package files
+
+import "io/fs"
+
+// Produces a key identifying a directory by the given path, since inodes are unavailable.
+func fileKey(_ fs.FileInfo, path string) any {
+	return path
+}
@@ -3,8 +3,11 @@ package files_test
 import (
 	"fmt"
 	"os"
+	"slices"
 	"snekcheck/internal/files"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
@@ -37,7 +40,7 @@ func TestIterTree(t *testing.T) {
 
 		var yieldedDirs uint = 0
 		var yieldedFiles uint = 0
-		for _, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("parent")) {
+		for _, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("parent"), files.TreeOptions{}) {
 			if fileInfo.IsDir() {
 				yieldedDirs += 1
 			} else {
@@ -64,7 +67,7 @@ func TestIterTree(t *testing.T) {
 
 		var yieldedDirs uint = 0
 		var yieldedFiles uint = 0
-		for _, fileInfo := range files.IterTree(fs, match, files.NewPath("grandparent")) {
+		for _, fileInfo := range files.IterTree(fs, match, files.NewPath("grandparent"), files.TreeOptions{}) {
 			if fileInfo.IsDir() {
 				yieldedDirs += 1
 			} else {
@@ -83,7 +86,7 @@ func TestIterTree(t *testing.T) {
 
 		var yieldedDirs uint = 0
 		var yieldedFiles uint = 0
-		for _, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("grandparent")) {
+		for _, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("grandparent"), files.TreeOptions{}) {
 			if fileInfo.IsDir() {
 				yieldedDirs += 1
 			} else {
@@ -102,7 +105,7 @@ func TestIterTree(t *testing.T) {
 
 		var yieldedDirs uint = 0
 		var yieldedFiles uint = 0
-		for _, fileInfo := range files.IterTree(fs, matchNone, files.NewPath("grandparent")) {
+		for _, fileInfo := range files.IterTree(fs, matchNone, files.NewPath("grandparent"), files.TreeOptions{}) {
 			if fileInfo.IsDir() {
 				yieldedDirs += 1
 			} else {
@@ -119,7 +122,7 @@ func TestIterTree(t *testing.T) {
 
 		var yieldedDirs uint = 0
 		var yieldedFiles uint = 0
-		for _, fileInfo := range files.IterTree(fs, matchNone, files.NewPath("invalid")) {
+		for _, fileInfo := range files.IterTree(fs, matchNone, files.NewPath("invalid"), files.TreeOptions{}) {
 			if fileInfo.IsDir() {
 				yieldedDirs += 1
 			} else {
@@ -139,7 +142,7 @@ func TestIterTree(t *testing.T) {
 		grandparentYielded := false
 		parentYielded := false
 		parent2Yielded := false
-		for path := range files.IterTree(fs, matchAll, files.NewPath("grandparent")) {
+		for path := range files.IterTree(fs, matchAll, files.NewPath("grandparent"), files.TreeOptions{}) {
 			if !grandparentYielded && path.Base() == "grandparent" {
 				grandparentYielded = true
 				continue
@@ -164,4 +167,222 @@ func TestIterTree(t *testing.T) {
 			}
 		}
 	})
+	t.Run("yields distinct paths that may be retained", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"grandparent/parent1": 10,
+			"grandparent/parent2": 10,
+		})
+
+		var paths []files.Path
+		var expected []string
+		for path := range files.IterTree(fs, matchAll, files.NewPath("grandparent"), files.TreeOptions{}) {
+			paths = append(paths, path)
+			expected = append(expected, path.String())
+		}
+		actual := make([]string, len(paths))
+		for i, path := range paths {
+			actual[i] = path.String()
+		}
+		assert.Equal(t, expected, actual)
+		assert.Len(t, actual, 23)
+	})
+	t.Run("yields identical, sorted paths when reading directories concurrently", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"grandparent/parent1":        10,
+			"grandparent/parent1/child1": 5,
+			"grandparent/parent2":        12,
+			"grandparent/parent3/child2": 3,
+		})
+		var match files.Matcher = func(path files.Path, _ bool) bool { return path.Base() != "child1" }
+		iterPaths := func(jobs int) []string {
+			var paths []string
+			for path := range files.IterTree(fs, match, files.NewPath("grandparent"), files.TreeOptions{Jobs: jobs}) {
+				paths = append(paths, path.String())
+			}
+			return paths
+		}
+
+		expected := iterPaths(1)
+		assert.Len(t, expected, 30)
+		assert.True(t, slices.IsSorted(expected))
+		for range 10 {
+			assert.Equal(t, expected, iterPaths(8))
+		}
+	})
+	t.Run("stops reading directories concurrently upon break", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"grandparent/parent1": 10,
+			"grandparent/parent2": 10,
+		})
+
+		for path := range files.IterTree(fs, matchAll, files.NewPath("grandparent"), files.TreeOptions{Jobs: 4}) {
+			if path.Base() == "parent1" {
+				break
+			}
+		}
+	})
+	t.Run("reads unmodified directories from the cache", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"root":     1,
+			"root/a":   2,
+			"root/a/b": 1,
+		})
+		cache := &mapCache{entries: make(map[string][]os.FileInfo)}
+		var expected []string
+		for path := range files.IterTree(fs, matchAll, files.NewPath("root"), files.TreeOptions{Cache: cache}) {
+			expected = append(expected, path.String())
+		}
+		require.Len(t, cache.entries, 3)
+		require.Zero(t, cache.loads)
+
+		// Cached entries are produced without reading the directory again
+		require.Nil(t, fs.Remove("root/a/b/0"))
+		var actual []string
+		for path := range files.IterTree(fs, matchAll, files.NewPath("root"), files.TreeOptions{Cache: cache}) {
+			actual = append(actual, path.String())
+		}
+		assert.Equal(t, 3, cache.loads)
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("does not follow symbolic links by default", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"root/dir": 1,
+			"outside":  2,
+		})
+		require.Nil(t, fs.Symlink("../outside", "root/link"))
+		require.Nil(t, fs.Symlink("..", "root/dir/loop"))
+
+		paths := make(map[string]bool)
+		for path, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("root"), files.TreeOptions{}) {
+			paths[path.String()] = fileInfo.IsDir()
+		}
+		assert.Equal(t, map[string]bool{
+			"root": true, "root/dir": true, "root/dir/0": false, "root/dir/loop": false, "root/link": false,
+		}, paths)
+	})
+	t.Run("follows symbolic links without revisiting directories", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"root/dir": 1,
+			"outside":  2,
+		})
+		require.Nil(t, fs.Symlink("../outside", "root/link"))
+		require.Nil(t, fs.Symlink("..", "root/dir/loop"))
+		require.Nil(t, fs.Symlink("../../outside", "root/dir/again"))
+
+		paths := make(map[string]bool)
+		for path, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("root"), files.TreeOptions{FollowSymlinks: true}) {
+			paths[path.String()] = fileInfo.IsDir()
+		}
+		assert.Equal(t, map[string]bool{
+			"root": true, "root/dir": true, "root/dir/0": false, "root/dir/loop": true,
+			"root/dir/again": true, "root/dir/again/0": false, "root/dir/again/1": false, "root/link": true,
+		}, paths)
+	})
+	t.Run("descends into a starting path that is a symbolic link to a directory", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"real": 1,
+		})
+		require.Nil(t, fs.Symlink("real", "link"))
+		require.Nil(t, fs.Symlink(".", "real/inner"))
+
+		paths := make(map[string]bool)
+		for path, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("link"), files.TreeOptions{}) {
+			paths[path.String()] = fileInfo.IsDir()
+		}
+		assert.Equal(t, map[string]bool{
+			"link": true, "link/0": false, "link/inner": false,
+		}, paths)
+	})
+	t.Run("produces a starting path that is a dangling symbolic link as a link", func(t *testing.T) {
+		fs := memfs.New()
+		require.Nil(t, fs.Symlink("missing", "dangling"))
+
+		var modes []os.FileMode
+		for _, fileInfo := range files.IterTree(fs, matchAll, files.NewPath("dangling"), files.TreeOptions{}) {
+			modes = append(modes, fileInfo.Mode()&os.ModeSymlink)
+		}
+		assert.Equal(t, []os.FileMode{os.ModeSymlink}, modes)
+	})
+	t.Run("breaks cycles between symbolic links", func(t *testing.T) {
+		fs := initFs(map[string]uint{
+			"root/a": 0,
+			"root/b": 0,
+		})
+		require.Nil(t, fs.Symlink("../b", "root/a/to_b"))
+		require.Nil(t, fs.Symlink("../a", "root/b/to_a"))
+
+		var count int
+		for range files.IterTree(fs, matchAll, files.NewPath("root"), files.TreeOptions{FollowSymlinks: true}) {
+			count++
+			require.Less(t, count, 100)
+		}
+	})
+}
+
+func BenchmarkIterTree(b *testing.B) {
+	// One million files, in one hundred directories of one hundred directories of one hundred files
+	fs := memfs.New()
+	for i := range 100 {
+		for j := range 100 {
+			dir := fmt.Sprintf("root/%d/%d", i, j)
+			require.Nil(b, fs.MkdirAll(dir, os.ModeDir))
+			for k := range 100 {
+				f, createErr := fs.Create(fmt.Sprintf("%s/%d", dir, k))
+				require.Nil(b, createErr)
+				require.Nil(b, f.Close())
+			}
+		}
+	}
+	var matchAll files.Matcher = func(_ files.Path, _ bool) bool { return true }
+
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				var count int
+				for range files.IterTree(fs, matchAll, files.NewPath("root"), files.TreeOptions{Jobs: jobs}) {
+					count++
+				}
+				require.Equal(b, 1+100+100*100+100*100*100, count)
+			}
+		})
+	}
+}
+
+func TestIsDanglingSymlink(t *testing.T) {
+	t.Parallel()
+	fs := memfs.New()
+	_, createErr := fs.Create("target")
+	require.Nil(t, createErr)
+	require.Nil(t, fs.Symlink("target", "link"))
+	require.Nil(t, fs.Symlink("missing", "dangling"))
+
+	for name, expected := range map[string]bool{"target": false, "link": false, "dangling": true} {
+		fileInfo, statErr := fs.Lstat(name)
+		require.Nil(t, statErr)
+		assert.Equal(t, expected, files.IsDanglingSymlink(fs, files.NewPath(name), fileInfo), name)
+	}
+}
+
+// A DirCache that ignores modification times.
+type mapCache struct {
+	mu      sync.Mutex
+	entries map[string][]os.FileInfo
+	loads   int
+}
+
+func (c *mapCache) Load(dir files.Path, _ time.Time) ([]os.FileInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries, ok := c.entries[dir.String()]
+	if ok {
+		c.loads++
+	}
+	return entries, ok
+}
+
+func (c *mapCache) Store(dir files.Path, _ time.Time, entries []os.FileInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[dir.String()] = entries
 }
@@ -0,0 +1,23 @@
+//go:build unix
+
+package files
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// A directory's device and inode numbers, which identify it regardless of the path it is reached by.
+type inode struct {
+	device uint64
+	number uint64
+}
+
+// Produces a key identifying a directory, preferring its device and inode numbers.
+// Falls back to the given path for filesystems without inodes, such as in-memory filesystems.
+func fileKey(fileInfo fs.FileInfo, path string) any {
+	if stat, ok := fileInfo.Sys().(*syscall.Stat_t); ok {
+		return inode{device: uint64(stat.Dev), number: stat.Ino}
+	}
+	return path
+}
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// A JSON-RPC message received from the client, either a request or a notification.
+type message struct {
+	// The ID of a request, which is absent for notifications.
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// A JSON-RPC message sent to the client, either a response or a notification.
+type outgoing struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  any              `json:"params,omitempty"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// The error of a failed request.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes.
+const (
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+)
+
+// Reads a single message, framed by a Content-Length header.
+func readMessage(r *bufio.Reader) (msg message, err error) {
+	header, headerErr := textproto.NewReader(r).ReadMIMEHeader()
+	if headerErr != nil {
+		return message{}, headerErr
+	}
+	length, lengthErr := strconv.Atoi(header.Get("Content-Length"))
+	if lengthErr != nil || length < 0 {
+		return message{}, fmt.Errorf("invalid Content-Length header %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, readErr := io.ReadFull(r, body); readErr != nil {
+		return message{}, readErr
+	}
+	if unmarshalErr := json.Unmarshal(body, &msg); unmarshalErr != nil {
+		return message{}, fmt.Errorf("invalid message: %w", unmarshalErr)
+	}
+	return msg, nil
+}
+
+// Writes a single message, framed by a Content-Length header.
+func writeMessage(w io.Writer, msg outgoing) error {
+	msg.JSONRPC = "2.0"
+	body, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	if _, writeErr := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body); writeErr != nil {
+		return writeErr
+	}
+	return nil
+}
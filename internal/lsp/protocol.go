@@ -0,0 +1,124 @@
+package lsp
+
+// The subset of Language Server Protocol types used by snekcheck.
+// See https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/.
+
+type initializeParams struct {
+	RootURI          string            `json:"rootUri"`
+	WorkspaceFolders []workspaceFolder `json:"workspaceFolders"`
+}
+
+type workspaceFolder struct {
+	URI string `json:"uri"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
+
+type serverInfo struct {
+	Name string `json:"name"`
+}
+
+type serverCapabilities struct {
+	CodeActionProvider codeActionOptions     `json:"codeActionProvider"`
+	Workspace          workspaceCapabilities `json:"workspace"`
+}
+
+type codeActionOptions struct {
+	CodeActionKinds []string `json:"codeActionKinds"`
+}
+
+type workspaceCapabilities struct {
+	FileOperations fileOperations `json:"fileOperations"`
+}
+
+type fileOperations struct {
+	DidCreate fileOperationRegistration `json:"didCreate"`
+	DidRename fileOperationRegistration `json:"didRename"`
+	DidDelete fileOperationRegistration `json:"didDelete"`
+}
+
+type fileOperationRegistration struct {
+	Filters []fileOperationFilter `json:"filters"`
+}
+
+type fileOperationFilter struct {
+	Scheme  string               `json:"scheme"`
+	Pattern fileOperationPattern `json:"pattern"`
+}
+
+type fileOperationPattern struct {
+	Glob string `json:"glob"`
+}
+
+type createFilesParams struct {
+	Files []struct {
+		URI string `json:"uri"`
+	} `json:"files"`
+}
+
+type renameFilesParams struct {
+	Files []struct {
+		OldURI string `json:"oldUri"`
+		NewURI string `json:"newUri"`
+	} `json:"files"`
+}
+
+type deleteFilesParams = createFilesParams
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Code     string    `json:"code,omitempty"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+// The severity of diagnostics for invalid names.
+const severityError = 1
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type codeActionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Context struct {
+		Diagnostics []diagnostic `json:"diagnostics"`
+	} `json:"context"`
+}
+
+type codeAction struct {
+	Title       string        `json:"title"`
+	Kind        string        `json:"kind"`
+	Diagnostics []diagnostic  `json:"diagnostics"`
+	Edit        workspaceEdit `json:"edit"`
+}
+
+// The kind of code actions that rename invalid files.
+const kindQuickFix = "quickfix"
+
+type workspaceEdit struct {
+	DocumentChanges []renameFile `json:"documentChanges"`
+}
+
+type renameFile struct {
+	Kind   string `json:"kind"`
+	OldURI string `json:"oldUri"`
+	NewURI string `json:"newUri"`
+}
@@ -0,0 +1,327 @@
+// Package lsp serves diagnostics for invalid names over the Language Server Protocol,
+// so that editors report invalid names, and offer to rename them, as files are created and renamed.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"snekcheck/internal/files"
+	"strings"
+)
+
+// The name of the server, and the source of its diagnostics.
+const name = "snekcheck"
+
+// Options for serving diagnostics.
+type Options struct {
+	// Checks file trees recursively, producing the diagnostics of their invalid paths.
+	Check func(paths []files.Path) []Diagnostic
+	// The file trees that are checked when the client specifies no workspace folders.
+	Roots []files.Path
+}
+
+// A rule violated by an invalid path.
+type Diagnostic struct {
+	Path files.Path
+	// The ID of the violated rule, if any.
+	Rule    string
+	Message string
+	// The name that the path can be renamed to, if any.
+	Fix string
+}
+
+// A language server that publishes diagnostics for invalid names.
+type Server struct {
+	options Options
+	// The checked file trees.
+	roots []files.Path
+	// The published diagnostics of invalid paths, keyed by their absolute string.
+	invalid map[string][]Diagnostic
+	// Whether the client has requested the server to shut down.
+	shutdown bool
+
+	w io.Writer
+	// The first failure to write a message.
+	writeErr error
+}
+
+// Constructs a new Server.
+func NewServer(options Options) *Server {
+	if options.Check == nil {
+		panic("invalid options")
+	}
+	return &Server{options: options, invalid: make(map[string][]Diagnostic)}
+}
+
+// Serves a client until it exits.
+// Fails if the connection fails, or if the client exits without requesting a shut down first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	reader := bufio.NewReader(r)
+	for {
+		msg, readErr := readMessage(reader)
+		if readErr != nil {
+			return fmt.Errorf("failed to read message: %w", readErr)
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("client exited without shutting down")
+			}
+			return nil
+		}
+
+		result, handleErr := s.handle(msg)
+		if msg.ID != nil {
+			response := outgoing{ID: msg.ID, Error: handleErr}
+			if handleErr == nil {
+				encoded, marshalErr := json.Marshal(result)
+				if marshalErr != nil {
+					return fmt.Errorf("failed to encode result: %w", marshalErr)
+				}
+				response.Result = (*json.RawMessage)(&encoded)
+			}
+			s.send(response)
+		}
+		if s.writeErr != nil {
+			return fmt.Errorf("failed to write message: %w", s.writeErr)
+		}
+	}
+}
+
+// Handles a single request or notification, producing the result of a request.
+func (s *Server) handle(msg message) (result any, err *responseError) {
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if unmarshalErr := json.Unmarshal(msg.Params, &params); unmarshalErr != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: unmarshalErr.Error()}
+		}
+		s.initialize(params)
+		return initializeResult{Capabilities: capabilities(), ServerInfo: serverInfo{Name: name}}, nil
+	case "initialized":
+		s.check(s.roots)
+	case "shutdown":
+		s.shutdown = true
+	case "workspace/didCreateFiles":
+		var params createFilesParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			var paths []files.Path
+			for _, file := range params.Files {
+				if path, ok := uriToPath(file.URI); ok {
+					paths = append(paths, path)
+				}
+			}
+			s.check(paths)
+		}
+	case "workspace/didRenameFiles":
+		var params renameFilesParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			var paths []files.Path
+			for _, file := range params.Files {
+				if oldPath, ok := uriToPath(file.OldURI); ok {
+					s.forget(oldPath)
+				}
+				if newPath, ok := uriToPath(file.NewURI); ok {
+					paths = append(paths, newPath)
+				}
+			}
+			s.check(paths)
+		}
+	case "workspace/didDeleteFiles":
+		var params deleteFilesParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			for _, file := range params.Files {
+				if path, ok := uriToPath(file.URI); ok {
+					s.forget(path)
+				}
+			}
+		}
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if unmarshalErr := json.Unmarshal(msg.Params, &params); unmarshalErr != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: unmarshalErr.Error()}
+		}
+		return s.codeActions(params), nil
+	default:
+		if msg.ID != nil {
+			return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+// Determines the file trees to check from the client's workspace.
+func (s *Server) initialize(params initializeParams) {
+	for _, folder := range params.WorkspaceFolders {
+		if path, ok := uriToPath(folder.URI); ok {
+			s.roots = append(s.roots, path)
+		}
+	}
+	if len(s.roots) == 0 {
+		if path, ok := uriToPath(params.RootURI); ok {
+			s.roots = append(s.roots, path)
+		}
+	}
+	if len(s.roots) == 0 {
+		s.roots = s.options.Roots
+	}
+}
+
+// Checks file trees, publishing diagnostics for their invalid paths,
+// and clearing the diagnostics of paths within them that are no longer invalid.
+func (s *Server) check(paths []files.Path) {
+	if len(paths) == 0 {
+		return
+	}
+
+	invalid := make(map[string][]Diagnostic)
+	for _, d := range s.options.Check(paths) {
+		invalid[key(d.Path)] = append(invalid[key(d.Path)], d)
+	}
+	for _, path := range s.sortedInvalid() {
+		if _, ok := invalid[key(path)]; !ok && slices.ContainsFunc(paths, func(p files.Path) bool { return p.Contains(path) }) {
+			s.clear(path)
+		}
+	}
+	for _, path := range sortedPaths(invalid) {
+		diagnostics := invalid[key(path)]
+		s.invalid[key(path)] = diagnostics
+		published := []diagnostic{}
+		for _, d := range diagnostics {
+			published = append(published, newDiagnostic(d))
+		}
+		s.publish(path, published)
+	}
+}
+
+// Clears the diagnostics of the invalid paths within a file tree that no longer exists.
+func (s *Server) forget(root files.Path) {
+	for _, path := range s.sortedInvalid() {
+		if root.Contains(path) {
+			s.clear(path)
+		}
+	}
+}
+
+// Clears the diagnostics of an invalid path.
+func (s *Server) clear(path files.Path) {
+	delete(s.invalid, key(path))
+	s.publish(path, []diagnostic{})
+}
+
+// Produces the invalid paths with published diagnostics, sorted.
+func (s *Server) sortedInvalid() []files.Path {
+	return sortedPaths(s.invalid)
+}
+
+// Produces the paths of diagnostics keyed by path, sorted.
+func sortedPaths(diagnostics map[string][]Diagnostic) []files.Path {
+	var paths []files.Path
+	for ds := range maps.Values(diagnostics) {
+		paths = append(paths, ds[0].Path)
+	}
+	slices.SortFunc(paths, slices.Compare[files.Path])
+	return paths
+}
+
+// Produces code actions that rename an invalid file to the fix of its diagnostics, if any.
+func (s *Server) codeActions(params codeActionParams) []codeAction {
+	actions := []codeAction{}
+	path, ok := uriToPath(params.TextDocument.URI)
+	if !ok {
+		return actions
+	}
+	fixable := slices.IndexFunc(s.invalid[key(path)], func(d Diagnostic) bool { return d.Fix != "" })
+	if fixable == -1 {
+		return actions
+	}
+	newName := s.invalid[key(path)][fixable].Fix
+
+	diagnostics := []diagnostic{}
+	for _, d := range params.Context.Diagnostics {
+		if d.Source == name {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	newPath := path.Parent().Join(newName)
+	return append(actions, codeAction{
+		Title:       "Rename to " + newName,
+		Kind:        kindQuickFix,
+		Diagnostics: diagnostics,
+		Edit: workspaceEdit{DocumentChanges: []renameFile{
+			{Kind: "rename", OldURI: pathToURI(path), NewURI: pathToURI(newPath)},
+		}},
+	})
+}
+
+// Publishes the diagnostics of a path, replacing any previously published ones.
+func (s *Server) publish(path files.Path, diagnostics []diagnostic) {
+	s.send(outgoing{
+		Method: "textDocument/publishDiagnostics",
+		Params: publishDiagnosticsParams{URI: pathToURI(path), Diagnostics: diagnostics},
+	})
+}
+
+// Sends a message to the client, keeping track of the first failure.
+func (s *Server) send(msg outgoing) {
+	if s.writeErr == nil {
+		s.writeErr = writeMessage(s.w, msg)
+	}
+}
+
+// Produces the capabilities of the server.
+// Clients are asked to notify the server of every file operation in the workspace.
+func capabilities() serverCapabilities {
+	registration := fileOperationRegistration{Filters: []fileOperationFilter{
+		{Scheme: "file", Pattern: fileOperationPattern{Glob: "**"}},
+	}}
+	return serverCapabilities{
+		CodeActionProvider: codeActionOptions{CodeActionKinds: []string{kindQuickFix}},
+		Workspace: workspaceCapabilities{FileOperations: fileOperations{
+			DidCreate: registration,
+			DidRename: registration,
+			DidDelete: registration,
+		}},
+	}
+}
+
+// Converts the diagnostic of an invalid path, which spans the start of the file.
+// The violated rule is reported as the code of the diagnostic.
+func newDiagnostic(d Diagnostic) diagnostic {
+	message := d.Message
+	if message == "" {
+		message = fmt.Sprintf("invalid name %q", d.Path.Base())
+	}
+	return diagnostic{
+		Severity: severityError,
+		Code:     d.Rule,
+		Source:   name,
+		Message:  message,
+	}
+}
+
+// Converts a file URI to an absolute path.
+func uriToPath(uri string) (path files.Path, ok bool) {
+	parsed, parseErr := url.Parse(uri)
+	if parseErr != nil || parsed.Scheme != "file" || !strings.HasPrefix(parsed.Path, "/") {
+		return nil, false
+	}
+	return files.NewPath(filepath.FromSlash(strings.TrimSuffix(parsed.Path, "/"))), true
+}
+
+// Converts an absolute path to a file URI.
+func pathToURI(path files.Path) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(strings.Join(path, string(filepath.Separator)))}).String()
+}
+
+// Produces the key of a path in the set of invalid paths.
+func key(path files.Path) string {
+	return strings.Join(path, "/")
+}
@@ -0,0 +1,253 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"slices"
+	"snekcheck/internal/files"
+	"snekcheck/internal/lsp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// An in-process language client.
+type client struct {
+	t      *testing.T
+	w      io.WriteCloser
+	r      *bufio.Reader
+	nextID int
+	// The result of serving the client.
+	served chan error
+}
+
+// A message received by the client.
+type received struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+// Starts serving an in-process client.
+func newClient(t *testing.T, options lsp.Options) *client {
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	c := &client{t: t, w: clientWriter, r: bufio.NewReader(clientReader), served: make(chan error, 1)}
+	go func() {
+		c.served <- lsp.NewServer(options).Serve(serverReader, serverWriter)
+		serverWriter.Close()
+	}()
+	return c
+}
+
+// Sends a notification.
+func (c *client) notify(method string, params any) {
+	c.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// Sends a request, producing its response.
+func (c *client) request(method string, params any) received {
+	c.nextID++
+	c.write(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	msg := c.read()
+	require.NotNil(c.t, msg.ID)
+	require.Equal(c.t, c.nextID, *msg.ID)
+	return msg
+}
+
+// Reads the URIs and messages of the next published diagnostics.
+func (c *client) diagnostics() (uri string, messages []string) {
+	msg := c.read()
+	require.Equal(c.t, "textDocument/publishDiagnostics", msg.Method)
+	var params struct {
+		URI         string `json:"uri"`
+		Diagnostics []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+			Source  string `json:"source"`
+		} `json:"diagnostics"`
+	}
+	require.Nil(c.t, json.Unmarshal(msg.Params, &params))
+	messages = []string{}
+	for _, d := range params.Diagnostics {
+		assert.Equal(c.t, "snekcheck", d.Source)
+		assert.Equal(c.t, "case", d.Code)
+		messages = append(messages, d.Message)
+	}
+	return params.URI, messages
+}
+
+func (c *client) write(msg map[string]any) {
+	body, marshalErr := json.Marshal(msg)
+	require.Nil(c.t, marshalErr)
+	_, writeErr := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.Nil(c.t, writeErr)
+}
+
+func (c *client) read() (msg received) {
+	header, headerErr := textproto.NewReader(c.r).ReadMIMEHeader()
+	require.Nil(c.t, headerErr)
+	length, lengthErr := strconv.Atoi(header.Get("Content-Length"))
+	require.Nil(c.t, lengthErr)
+	body := make([]byte, length)
+	_, readErr := io.ReadFull(c.r, body)
+	require.Nil(c.t, readErr)
+	require.Nil(c.t, json.Unmarshal(body, &msg))
+	return msg
+}
+
+// Checks a fake file tree, in which names containing uppercase letters are invalid.
+// Invalid names are fixed by converting them to lowercase, unless the lowercase name is taken.
+// The tree is safe to modify while it is being checked by the server.
+type fakeTree struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+// Adds paths to the tree.
+func (f *fakeTree) add(paths ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, paths...)
+}
+
+// Renames a path in the tree.
+func (f *fakeTree) rename(oldPath string, newPath string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths[slices.Index(f.paths, oldPath)] = newPath
+}
+
+func (f *fakeTree) check(roots []files.Path) (diagnostics []lsp.Diagnostic) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.paths {
+		path := files.NewPath(p)
+		fix := strings.ToLower(path.Base())
+		if fix == path.Base() || !slices.ContainsFunc(roots, func(root files.Path) bool { return root.Contains(path) }) {
+			continue
+		}
+		if slices.Contains(f.paths, strings.TrimSuffix(p, path.Base())+fix) {
+			fix = ""
+		}
+		diagnostics = append(diagnostics, lsp.Diagnostic{Path: path, Rule: "case", Message: fmt.Sprintf("%q is not lowercase", path.Base()), Fix: fix})
+	}
+	return
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	t.Run("publishes diagnostics as files are created and renamed", func(t *testing.T) {
+		tree := &fakeTree{paths: []string{"/ws", "/ws/Bad.go", "/ws/good.go"}}
+		c := newClient(t, lsp.Options{Check: tree.check})
+
+		response := c.request("initialize", map[string]any{"workspaceFolders": []map[string]string{{"uri": "file:///ws"}}})
+		require.Nil(t, response.Error)
+		assert.Contains(t, string(response.Result), `"didRename"`)
+		c.notify("initialized", map[string]any{})
+		uri, messages := c.diagnostics()
+		assert.Equal(t, "file:///ws/Bad.go", uri)
+		assert.Equal(t, []string{`"Bad.go" is not lowercase`}, messages)
+
+		// Offers to rename the invalid file
+		response = c.request("textDocument/codeAction", map[string]any{
+			"textDocument": map[string]string{"uri": "file:///ws/Bad.go"},
+			"context":      map[string]any{"diagnostics": []any{}},
+		})
+		require.Nil(t, response.Error)
+		var actions []struct {
+			Title string `json:"title"`
+			Edit  struct {
+				DocumentChanges []map[string]string `json:"documentChanges"`
+			} `json:"edit"`
+		}
+		require.Nil(t, json.Unmarshal(response.Result, &actions))
+		require.Len(t, actions, 1)
+		assert.Equal(t, "Rename to bad.go", actions[0].Title)
+		assert.Equal(t, []map[string]string{{"kind": "rename", "oldUri": "file:///ws/Bad.go", "newUri": "file:///ws/bad.go"}},
+			actions[0].Edit.DocumentChanges)
+
+		// Clears the diagnostics of renamed files
+		tree.rename("/ws/Bad.go", "/ws/bad.go")
+		c.notify("workspace/didRenameFiles", map[string]any{"files": []map[string]string{{"oldUri": "file:///ws/Bad.go", "newUri": "file:///ws/bad.go"}}})
+		uri, messages = c.diagnostics()
+		assert.Equal(t, "file:///ws/Bad.go", uri)
+		assert.Empty(t, messages)
+
+		// Checks created directories recursively
+		tree.add("/ws/New Dir", "/ws/New Dir/Inner")
+		c.notify("workspace/didCreateFiles", map[string]any{"files": []map[string]string{{"uri": "file:///ws/New%20Dir"}}})
+		uri, _ = c.diagnostics()
+		assert.Equal(t, "file:///ws/New%20Dir", uri)
+		uri, _ = c.diagnostics()
+		assert.Equal(t, "file:///ws/New%20Dir/Inner", uri)
+
+		// Clears the diagnostics within deleted directories
+		c.notify("workspace/didDeleteFiles", map[string]any{"files": []map[string]string{{"uri": "file:///ws/New%20Dir"}}})
+		uri, messages = c.diagnostics()
+		assert.Equal(t, "file:///ws/New%20Dir", uri)
+		assert.Empty(t, messages)
+		uri, messages = c.diagnostics()
+		assert.Equal(t, "file:///ws/New%20Dir/Inner", uri)
+		assert.Empty(t, messages)
+
+		response = c.request("shutdown", nil)
+		require.Nil(t, response.Error)
+		assert.Equal(t, "null", string(response.Result))
+		c.notify("exit", nil)
+		assert.Nil(t, <-c.served)
+	})
+	t.Run("checks the default roots without workspace folders", func(t *testing.T) {
+		tree := &fakeTree{paths: []string{"/ws/Bad.go", "/other/Bad.go"}}
+		c := newClient(t, lsp.Options{Check: tree.check, Roots: []files.Path{files.NewPath("/other")}})
+
+		c.request("initialize", map[string]any{})
+		c.notify("initialized", map[string]any{})
+		uri, _ := c.diagnostics()
+		assert.Equal(t, "file:///other/Bad.go", uri)
+	})
+	t.Run("offers no code actions for valid files", func(t *testing.T) {
+		tree := &fakeTree{paths: []string{"/ws/good.go"}}
+		c := newClient(t, lsp.Options{Check: tree.check})
+
+		c.request("initialize", map[string]any{"rootUri": "file:///ws"})
+		response := c.request("textDocument/codeAction", map[string]any{"textDocument": map[string]string{"uri": "file:///ws/good.go"}})
+		assert.Equal(t, "[]", string(response.Result))
+	})
+	t.Run("offers no code actions for unfixable files", func(t *testing.T) {
+		tree := &fakeTree{paths: []string{"/ws/Bad.go", "/ws/bad.go"}}
+		c := newClient(t, lsp.Options{Check: tree.check})
+
+		c.request("initialize", map[string]any{"rootUri": "file:///ws"})
+		c.notify("initialized", map[string]any{})
+		uri, messages := c.diagnostics()
+		assert.Equal(t, "file:///ws/Bad.go", uri)
+		assert.Equal(t, []string{`"Bad.go" is not lowercase`}, messages)
+		response := c.request("textDocument/codeAction", map[string]any{"textDocument": map[string]string{"uri": "file:///ws/Bad.go"}})
+		assert.Equal(t, "[]", string(response.Result))
+	})
+	t.Run("rejects unknown requests", func(t *testing.T) {
+		c := newClient(t, lsp.Options{Check: (&fakeTree{}).check})
+
+		response := c.request("workspace/unknown", nil)
+		require.NotNil(t, response.Error)
+		assert.Equal(t, -32601, response.Error.Code)
+	})
+	t.Run("fails if the client exits without shutting down", func(t *testing.T) {
+		c := newClient(t, lsp.Options{Check: (&fakeTree{}).check})
+
+		c.notify("exit", nil)
+		assert.NotNil(t, <-c.served)
+	})
+}
@@ -1,19 +1,54 @@
-// TODO: Package comment
+// Package patterns validates strings against naming conventions, and converts strings to follow them.
+// Strings are scanned byte by byte, since every character allowed by the conventions is ASCII.
 package patterns
 
-import "regexp"
+// Determines if a byte is a lowercase ASCII letter.
+func isLower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
 
-// Precompiled regular expressions.
-var (
-	// Matches several lowercase letters.
-	lowers = regexp.MustCompile(`[a-z]+`)
+// Determines if a byte is an uppercase ASCII letter.
+func isUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
 
-	// Matches a single separator.
-	separators = regexp.MustCompile(`[_\- ]`)
+// Determines if a byte is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
 
-	// Matches a single space.
-	spaces = regexp.MustCompile(`[ ]`)
+// Determines if a byte separates words.
+func isSeparator(c byte) bool {
+	return c == '_' || c == '-' || c == ' '
+}
 
-	// Matches several uppercase letters.
-	uppers = regexp.MustCompile(`[A-Z]+`)
-)
+// Determines if every byte of a string satisfies a predicate.
+func allBytes(s string, predicate func(c byte) bool) bool {
+	for i := 0; i < len(s); i++ {
+		if !predicate(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Maps every byte of a string, dropping the bytes that are mapped to zero, including zero itself.
+// Produces the string itself, without allocating, when no byte is changed.
+func mapBytes(s string, mapping func(c byte) byte) string {
+	i := 0
+	for i < len(s) && s[i] != 0 && mapping(s[i]) == s[i] {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+
+	mapped := make([]byte, i, len(s))
+	copy(mapped, s[:i])
+	for ; i < len(s); i++ {
+		if c := mapping(s[i]); c != 0 {
+			mapped = append(mapped, c)
+		}
+	}
+	return string(mapped)
+}
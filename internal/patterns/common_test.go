@@ -0,0 +1,33 @@
+package patterns_test
+
+import (
+	"snekcheck/internal/patterns"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Not parallel, since allocations are counted globally.
+func TestAllocations(t *testing.T) {
+	t.Run("does not allocate for valid input", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			input string
+			check func(string) bool
+			fix   func(string) string
+		}{
+			{name: "POSIX", input: "posix_FILE-name.md", check: patterns.IsPosixFileName, fix: patterns.ToPosixFileName},
+			{name: "SCREAMING_SNAKE_CASE", input: "SCREAMING_SNAKE.MD", check: patterns.IsScreamingSnakeCase, fix: patterns.ToScreamingSnakeCase},
+			{name: "snake_case", input: "snake_case.go", check: patterns.IsSnakeCase, fix: patterns.ToSnakeCase},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				allocs := testing.AllocsPerRun(100, func() {
+					tc.check(tc.input)
+					tc.fix(tc.input)
+				})
+				assert.Zero(t, allocs)
+			})
+		}
+	})
+}
@@ -1,30 +1,32 @@
 package patterns
 
-import (
-	"regexp"
-)
+// The maximum length of a POSIX filename.
+// The length limitation is enforced by file systems rather than POSIX,
+// but many file systems have a similar limit.
+const maxPosixFileNameLength = 255
 
-// Precompiled regular expressions.
-var (
-	// Matches characters that are not valid in POSIX filenames according to
-	// https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap03.html#tag_03_276.
-	invalidPosixFileNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9._\-]*`)
-
-	// Matches a valid POSIX filename according to
-	// https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap03.html#tag_03_276.
-	// The length limitation is enforced by file systems rather than POSIX,
-	// but many file systems have a similar limit.
-	validPosixFileName = regexp.MustCompile(`^[a-zA-Z0-9._][a-zA-Z0-9._\-]{0,254}$`)
-)
+// Determines if a byte is valid in POSIX filenames according to
+// https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap03.html#tag_03_276.
+func isPosixFileNameCharacter(c byte) bool {
+	return isLower(c) || isUpper(c) || isDigit(c) || c == '.' || c == '_' || c == '-'
+}
 
 // Determines if a string is a valid POSIX filename.
 func IsPosixFileName(s string) bool {
-	return validPosixFileName.MatchString(s)
+	return 0 < len(s) && len(s) <= maxPosixFileNameLength && s[0] != '-' && allBytes(s, isPosixFileNameCharacter)
 }
 
 // Attempts to convert a string to a valid POSIX filename.
 func ToPosixFileName(s string) string {
-	s = spaces.ReplaceAllLiteralString(s, "_")
-	s = invalidPosixFileNameCharacters.ReplaceAllLiteralString(s, "")
-	return s[:min(len(s), 255)]
+	s = mapBytes(s, func(c byte) byte {
+		switch {
+		case c == ' ':
+			return '_'
+		case isPosixFileNameCharacter(c):
+			return c
+		default:
+			return 0
+		}
+	})
+	return s[:min(len(s), maxPosixFileNameLength)]
 }
@@ -2,6 +2,7 @@ package patterns_test
 
 import (
 	"snekcheck/internal/patterns"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,11 +11,13 @@ import (
 
 func BenchmarkPosix(b *testing.B) {
 	b.Run("IsPosixFileName()", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			patterns.IsPosixFileName("Bench mark")
 		}
 	})
 	b.Run("ToPosixFileName()", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			patterns.ToPosixFileName("Bench mark")
 		}
@@ -22,9 +25,14 @@ func BenchmarkPosix(b *testing.B) {
 }
 
 func FuzzPosix(f *testing.F) {
+	for _, seed := range []string{"", "Fuzz me", "posix_FILE-name.md", "-_- ", "ünïcödé", "\xff\xfe", strings.Repeat("a ", 200)} {
+		f.Add(seed)
+	}
 	f.Fuzz(func(t *testing.T, input string) {
 		output := patterns.ToPosixFileName(input)
-		assert.True(t, patterns.IsPosixFileName(output))
+		assert.Equal(t, regexpIsPosixFileName(input), patterns.IsPosixFileName(input))
+		assert.Equal(t, regexpToPosixFileName(input), output)
+		assert.Equal(t, regexpIsPosixFileName(output), patterns.IsPosixFileName(output))
 		if patterns.IsPosixFileName(input) {
 			assert.Equal(t, input, output)
 		}
@@ -0,0 +1,51 @@
+package patterns_test
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Reference implementations of the patterns using regular expressions, against which the patterns are fuzzed.
+var (
+	lowers     = regexp.MustCompile(`[a-z]+`)
+	separators = regexp.MustCompile(`[_\- ]`)
+	spaces     = regexp.MustCompile(`[ ]`)
+	uppers     = regexp.MustCompile(`[A-Z]+`)
+
+	invalidPosixFileNameCharacters      = regexp.MustCompile(`[^a-zA-Z0-9._\-]*`)
+	validPosixFileName                  = regexp.MustCompile(`^[a-zA-Z0-9._][a-zA-Z0-9._\-]{0,254}$`)
+	invalidScreamingSnakeCaseCharacters = regexp.MustCompile(`[^A-Z0-9._]+`)
+	screamingSnakeCase                  = regexp.MustCompile(`^[A-Z0-9._]*$`)
+	invalidSnakeCaseCharacters          = regexp.MustCompile(`[^a-z0-9._]+`)
+	snakeCase                           = regexp.MustCompile(`^[a-z0-9._]*$`)
+)
+
+func regexpIsPosixFileName(s string) bool {
+	return validPosixFileName.MatchString(s)
+}
+
+func regexpToPosixFileName(s string) string {
+	s = spaces.ReplaceAllLiteralString(s, "_")
+	s = invalidPosixFileNameCharacters.ReplaceAllLiteralString(s, "")
+	return s[:min(len(s), 255)]
+}
+
+func regexpIsScreamingSnakeCase(s string) bool {
+	return screamingSnakeCase.MatchString(s)
+}
+
+func regexpToScreamingSnakeCase(s string) string {
+	s = separators.ReplaceAllLiteralString(s, "_")
+	s = lowers.ReplaceAllStringFunc(s, strings.ToUpper)
+	return invalidScreamingSnakeCaseCharacters.ReplaceAllLiteralString(s, "")
+}
+
+func regexpIsSnakeCase(s string) bool {
+	return snakeCase.MatchString(s)
+}
+
+func regexpToSnakeCase(s string) string {
+	s = separators.ReplaceAllLiteralString(s, "_")
+	s = uppers.ReplaceAllStringFunc(s, strings.ToLower)
+	return invalidSnakeCaseCharacters.ReplaceAllLiteralString(s, "")
+}
@@ -1,29 +1,27 @@
 package patterns
 
-import (
-	"regexp"
-	"strings"
-)
-
-// Precompiled regular expressions.
-var (
-	// Matches characters that are not valid in SCREAMING_SNAKE_CASE.
-	invalidScreamingSnakeCaseCharacters = regexp.MustCompile(`[^A-Z0-9._]+`)
-
-	// Matches a valid SCREAMING_SNAKE_CASE string.
-	screamingSnakeCase = regexp.MustCompile(`^[A-Z0-9._]*$`)
-)
+// Determines if a byte is valid in SCREAMING_SNAKE_CASE.
+func isScreamingSnakeCaseCharacter(c byte) bool {
+	return isUpper(c) || isDigit(c) || c == '.' || c == '_'
+}
 
 // Determines if a string is valid SCREAMING_SNAKE_CASE.
 func IsScreamingSnakeCase(s string) bool {
-	return screamingSnakeCase.MatchString(s)
+	return allBytes(s, isScreamingSnakeCaseCharacter)
 }
 
 // Attempts to convert a string to valid SCREAMING_SNAKE_CASE.
 func ToScreamingSnakeCase(s string) string {
-	s = separators.ReplaceAllLiteralString(s, "_")
-	s = lowers.ReplaceAllStringFunc(s, func(match string) string {
-		return strings.ToUpper(match)
+	return mapBytes(s, func(c byte) byte {
+		switch {
+		case isSeparator(c):
+			return '_'
+		case isLower(c):
+			return c + 'A' - 'a'
+		case isScreamingSnakeCaseCharacter(c):
+			return c
+		default:
+			return 0
+		}
 	})
-	return invalidScreamingSnakeCaseCharacters.ReplaceAllLiteralString(s, "")
 }
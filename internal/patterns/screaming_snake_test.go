@@ -2,6 +2,7 @@ package patterns_test
 
 import (
 	"snekcheck/internal/patterns"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,11 +11,13 @@ import (
 
 func BenchmarkScreamingSnakeCase(b *testing.B) {
 	b.Run("IsScreamingSnakeCase()", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			patterns.IsScreamingSnakeCase("Bench mark")
 		}
 	})
 	b.Run("ToScreamingSnakeCase()", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			patterns.ToScreamingSnakeCase("Bench mark")
 		}
@@ -22,8 +25,13 @@ func BenchmarkScreamingSnakeCase(b *testing.B) {
 }
 
 func FuzzScreamingSnakeCase(f *testing.F) {
+	for _, seed := range []string{"", "Fuzz me", "SCREAMING_SNAKE.MD", "-_- ", "ünïcödé", "\xff\xfe", strings.Repeat("a ", 200)} {
+		f.Add(seed)
+	}
 	f.Fuzz(func(t *testing.T, input string) {
 		output := patterns.ToScreamingSnakeCase(input)
+		assert.Equal(t, regexpIsScreamingSnakeCase(input), patterns.IsScreamingSnakeCase(input))
+		assert.Equal(t, regexpToScreamingSnakeCase(input), output)
 		assert.True(t, patterns.IsScreamingSnakeCase(output))
 		if patterns.IsScreamingSnakeCase(input) {
 			assert.Equal(t, input, output)
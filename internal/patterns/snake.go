@@ -1,29 +1,27 @@
 package patterns
 
-import (
-	"regexp"
-	"strings"
-)
-
-// Precompiled regular expressions.
-var (
-	// Matches characters that are not valid in snake_case.
-	invalidSnakeCaseCharacters = regexp.MustCompile(`[^a-z0-9._]+`)
-
-	// Matches a valid snake_case string.
-	snakeCase = regexp.MustCompile(`^[a-z0-9._]*$`)
-)
+// Determines if a byte is valid in snake_case.
+func isSnakeCaseCharacter(c byte) bool {
+	return isLower(c) || isDigit(c) || c == '.' || c == '_'
+}
 
 // Determines if a string is valid snake_case.
 func IsSnakeCase(s string) bool {
-	return snakeCase.MatchString(s)
+	return allBytes(s, isSnakeCaseCharacter)
 }
 
 // Attempts to convert a string to valid snake_case.
 func ToSnakeCase(s string) string {
-	s = separators.ReplaceAllLiteralString(s, "_")
-	s = uppers.ReplaceAllStringFunc(s, func(match string) string {
-		return strings.ToLower(match)
+	return mapBytes(s, func(c byte) byte {
+		switch {
+		case isSeparator(c):
+			return '_'
+		case isUpper(c):
+			return c + 'a' - 'A'
+		case isSnakeCaseCharacter(c):
+			return c
+		default:
+			return 0
+		}
 	})
-	return invalidSnakeCaseCharacters.ReplaceAllLiteralString(s, "")
 }
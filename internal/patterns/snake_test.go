@@ -2,6 +2,7 @@ package patterns_test
 
 import (
 	"snekcheck/internal/patterns"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,11 +11,13 @@ import (
 
 func BenchmarkSnakeCase(b *testing.B) {
 	b.Run("IsSnakeCase()", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			patterns.IsSnakeCase("Bench mark")
 		}
 	})
 	b.Run("ToSnakeCase()", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			patterns.ToSnakeCase("Bench mark")
 		}
@@ -22,8 +25,13 @@ func BenchmarkSnakeCase(b *testing.B) {
 }
 
 func FuzzSnakeCase(f *testing.F) {
+	for _, seed := range []string{"", "Fuzz me", "snake_case.go", "-_- ", "ünïcödé", "\xff\xfe", strings.Repeat("a ", 200)} {
+		f.Add(seed)
+	}
 	f.Fuzz(func(t *testing.T, input string) {
 		output := patterns.ToSnakeCase(input)
+		assert.Equal(t, regexpIsSnakeCase(input), patterns.IsSnakeCase(input))
+		assert.Equal(t, regexpToSnakeCase(input), output)
 		assert.True(t, patterns.IsSnakeCase(output))
 		if patterns.IsSnakeCase(input) {
 			assert.Equal(t, input, output)
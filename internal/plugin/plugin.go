@@ -0,0 +1,191 @@
+// Package plugin runs external rule plugins, which check names over a JSON-lines protocol.
+//
+// A plugin is an executable that reads a Request from each line of its standard input,
+// and writes a Response to a single line of its standard output for each request, in order.
+// It exits once its standard input is closed.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// A batch of paths to check.
+type Request struct {
+	Paths []Path `json:"paths"`
+}
+
+// A path to check.
+type Path struct {
+	// The absolute path.
+	Path string `json:"path"`
+	// Whether the path is a directory.
+	Dir bool `json:"dir,omitempty"`
+}
+
+// The diagnostics of a batch of paths. Valid paths have no diagnostics.
+type Response struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// A violation of one of the plugin's rules.
+type Diagnostic struct {
+	// The absolute path, which must be one of the requested paths.
+	Path string `json:"path"`
+	// The ID of the violated rule, for plugins with several rules.
+	Rule string `json:"rule,omitempty"`
+	// Describes how the path violates the rule.
+	Message string `json:"message,omitempty"`
+	// A suggested filename that satisfies the rule, if any.
+	Fix string `json:"fix,omitempty"`
+}
+
+// A running plugin.
+type Plugin struct {
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	// The standard error of the plugin, which is included in errors once it has exited.
+	stderr bytes.Buffer
+	// Whether the plugin has been waited for, and how it exited.
+	exited  bool
+	exitErr error
+}
+
+// Launches a plugin executable with arguments, in a working directory.
+// Fails if the executable cannot be started.
+func Start(name string, command []string, dir string) (*Plugin, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("plugin %s has no command", name)
+	}
+
+	p := &Plugin{name: name, cmd: exec.Command(command[0], command[1:]...)}
+	p.cmd.Dir = dir
+	p.cmd.Stderr = &p.stderr
+	stdin, stdinErr := p.cmd.StdinPipe()
+	if stdinErr != nil {
+		return nil, stdinErr
+	}
+	stdout, stdoutErr := p.cmd.StdoutPipe()
+	if stdoutErr != nil {
+		return nil, stdoutErr
+	}
+	if startErr := p.cmd.Start(); startErr != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", name, startErr)
+	}
+	p.stdin, p.stdout = stdin, bufio.NewReader(stdout)
+	return p, nil
+}
+
+// The name of the plugin.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Checks a batch of paths, producing the plugin's diagnostics.
+// Fails if the plugin cannot be communicated with, or responds with diagnostics about paths that were not requested,
+// or with suggested fixes that are not filenames.
+func (p *Plugin) Check(paths []Path) ([]Diagnostic, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	request, marshalErr := json.Marshal(Request{Paths: paths})
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	if _, writeErr := p.stdin.Write(append(request, '\n')); writeErr != nil {
+		return nil, p.fail(writeErr)
+	}
+	line, readErr := p.stdout.ReadBytes('\n')
+	if readErr != nil {
+		return nil, p.fail(readErr)
+	}
+
+	var response Response
+	if unmarshalErr := json.Unmarshal(line, &response); unmarshalErr != nil {
+		return nil, fmt.Errorf("invalid response from plugin %s: %w", p.name, unmarshalErr)
+	}
+	requested := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		requested[path.Path] = true
+	}
+	for _, d := range response.Diagnostics {
+		if !requested[d.Path] {
+			return nil, fmt.Errorf("invalid response from plugin %s: unrequested path %q", p.name, d.Path)
+		}
+		if d.Fix != "" && (d.Fix == "." || d.Fix == ".." || strings.ContainsRune(d.Fix, filepath.Separator)) {
+			return nil, fmt.Errorf("invalid response from plugin %s: fix %q is not a filename", p.name, d.Fix)
+		}
+	}
+	return response.Diagnostics, nil
+}
+
+// Closes the standard input of the plugin, and waits for it to exit.
+// Fails if the plugin exits unsuccessfully.
+func (p *Plugin) Close() error {
+	if waitErr := p.wait(); waitErr != nil {
+		return p.describe(waitErr)
+	}
+	return nil
+}
+
+// Closes the standard input of the plugin, and waits for it to exit, only once.
+func (p *Plugin) wait() error {
+	if !p.exited {
+		p.exited = true
+		p.stdin.Close()
+		p.exitErr = p.cmd.Wait()
+	}
+	return p.exitErr
+}
+
+// Describes a failure to communicate with the plugin, once it has exited.
+func (p *Plugin) fail(err error) error {
+	_ = p.wait()
+	return p.describe(err)
+}
+
+// Describes a failure of the plugin, including its standard error.
+func (p *Plugin) describe(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = errors.New("unexpected exit")
+	}
+	if stderr := strings.TrimSpace(p.stderr.String()); stderr != "" {
+		return fmt.Errorf("plugin %s failed: %w: %s", p.name, err, stderr)
+	}
+	return fmt.Errorf("plugin %s failed: %w", p.name, err)
+}
+
+// Serves the plugin protocol, checking each requested path until the requests end.
+// Intended for implementing plugins in Go.
+func Serve(r io.Reader, w io.Writer, check func(path Path) []Diagnostic) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	encoder := json.NewEncoder(w)
+	for scanner.Scan() {
+		var request Request
+		if unmarshalErr := json.Unmarshal(scanner.Bytes(), &request); unmarshalErr != nil {
+			return fmt.Errorf("invalid request: %w", unmarshalErr)
+		}
+		response := Response{Diagnostics: []Diagnostic{}}
+		for _, path := range request.Paths {
+			for _, d := range check(path) {
+				d.Path = path.Path
+				response.Diagnostics = append(response.Diagnostics, d)
+			}
+		}
+		if encodeErr := encoder.Encode(response); encodeErr != nil {
+			return encodeErr
+		}
+	}
+	return scanner.Err()
+}
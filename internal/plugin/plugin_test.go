@@ -0,0 +1,139 @@
+package plugin_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"snekcheck/internal/plugin"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The source of a fake plugin, whose behavior is selected by its only argument.
+const fakePlugin = `package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	mode := os.Args[1]
+	if mode == "crash" {
+		fmt.Fprintln(os.Stderr, "boom")
+		os.Exit(3)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var request struct {
+			Paths []struct {
+				Path string
+				Dir  bool
+			}
+		}
+		json.Unmarshal(scanner.Bytes(), &request)
+		diagnostics := []map[string]string{}
+		for _, path := range request.Paths {
+			switch {
+			case mode == "garbage":
+			case mode == "stranger":
+				diagnostics = append(diagnostics, map[string]string{"path": "/elsewhere"})
+			case !path.Dir:
+				base := filepath.Base(path.Path)
+				diagnostics = append(diagnostics, map[string]string{"path": path.Path, "rule": "fake", "message": "fake " + base, "fix": "fixed_" + base})
+			}
+		}
+		if mode == "garbage" {
+			fmt.Println("not json")
+			continue
+		}
+		json.NewEncoder(os.Stdout).Encode(map[string]any{"diagnostics": diagnostics})
+	}
+}
+`
+
+// Builds the fake plugin, producing the path of its executable.
+func buildFakePlugin(t *testing.T) string {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "fake_plugin")
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(fakePlugin), 0o644))
+	build := exec.Command("go", "build", "-o", executable, "main.go")
+	build.Dir = dir
+	output, buildErr := build.CombinedOutput()
+	require.Nil(t, buildErr, string(output))
+	return executable
+}
+
+func TestPlugin(t *testing.T) {
+	t.Parallel()
+	executable := buildFakePlugin(t)
+	t.Run("checks batches of paths", func(t *testing.T) {
+		p, startErr := plugin.Start("fake", []string{executable, "echo"}, t.TempDir())
+		require.Nil(t, startErr)
+
+		diagnostics, checkErr := p.Check([]plugin.Path{{Path: "/repo/a.go"}, {Path: "/repo/dir", Dir: true}})
+		require.Nil(t, checkErr)
+		assert.Equal(t, []plugin.Diagnostic{
+			{Path: "/repo/a.go", Rule: "fake", Message: "fake a.go", Fix: "fixed_a.go"},
+		}, diagnostics)
+
+		diagnostics, checkErr = p.Check([]plugin.Path{{Path: "/repo/b.go"}})
+		require.Nil(t, checkErr)
+		assert.Equal(t, []plugin.Diagnostic{
+			{Path: "/repo/b.go", Rule: "fake", Message: "fake b.go", Fix: "fixed_b.go"},
+		}, diagnostics)
+		assert.Nil(t, p.Close())
+	})
+	t.Run("fails on invalid responses", func(t *testing.T) {
+		for _, mode := range []string{"garbage", "stranger"} {
+			t.Run(mode, func(t *testing.T) {
+				p, startErr := plugin.Start("fake", []string{executable, mode}, t.TempDir())
+				require.Nil(t, startErr)
+
+				_, checkErr := p.Check([]plugin.Path{{Path: "/repo/a.go"}})
+				assert.NotNil(t, checkErr)
+				assert.Nil(t, p.Close())
+			})
+		}
+	})
+	t.Run("fails when the plugin exits", func(t *testing.T) {
+		p, startErr := plugin.Start("fake", []string{executable, "crash"}, t.TempDir())
+		require.Nil(t, startErr)
+
+		_, checkErr := p.Check([]plugin.Path{{Path: "/repo/a.go"}})
+		require.NotNil(t, checkErr)
+		assert.Contains(t, checkErr.Error(), "boom")
+		assert.NotNil(t, p.Close())
+	})
+	t.Run("fails to start missing executables", func(t *testing.T) {
+		_, startErr := plugin.Start("missing", []string{filepath.Join(t.TempDir(), "missing")}, t.TempDir())
+		assert.NotNil(t, startErr)
+	})
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+	t.Run("responds to each request", func(t *testing.T) {
+		requests := `{"paths":[{"path":"/repo/Bad.go"},{"path":"/repo/good.go"}]}` + "\n" + `{"paths":[]}` + "\n"
+		var responses bytes.Buffer
+		serveErr := plugin.Serve(strings.NewReader(requests), &responses, func(path plugin.Path) []plugin.Diagnostic {
+			if filepath.Base(path.Path) == "Bad.go" {
+				return []plugin.Diagnostic{{Message: "bad"}}
+			}
+			return nil
+		})
+		require.Nil(t, serveErr)
+		assert.Equal(t, `{"diagnostics":[{"path":"/repo/Bad.go","message":"bad"}]}`+"\n"+`{"diagnostics":[]}`+"\n", responses.String())
+	})
+	t.Run("fails on invalid requests", func(t *testing.T) {
+		serveErr := plugin.Serve(strings.NewReader("not json\n"), &bytes.Buffer{}, func(plugin.Path) []plugin.Diagnostic { return nil })
+		assert.NotNil(t, serveErr)
+	})
+}
@@ -0,0 +1,79 @@
+// Package ratchet enforces per-directory budgets of violations, which may only go down over time.
+package ratchet
+
+import (
+	"path"
+	"slices"
+	"snekcheck/internal/files"
+	"strings"
+)
+
+// The number of violations within a budgeted directory.
+type Result struct {
+	// The budgeted directory, relative to the configuration file.
+	Prefix string
+	// The maximum allowed number of violations.
+	Budget uint
+	// The actual number of violations.
+	Count uint
+}
+
+// Determines if the violations exceed their budget.
+func (r Result) Exceeded() bool {
+	return r.Count > r.Budget
+}
+
+// Determines if the budget could be lowered to the number of violations.
+func (r Result) Lowerable() bool {
+	return r.Count < r.Budget
+}
+
+// Counts invalid paths against the budgets of directory prefixes, relative to a directory.
+// Each path only counts against the most specific prefix containing it.
+// Paths outside of every budgeted prefix are produced as unbudgeted.
+// Only the budgets of prefixes within the counted roots produce results, since violations elsewhere were not counted.
+// Results are sorted by prefix.
+func Count(dir files.Path, budgets map[string]uint, roots []files.Path, invalidPaths []files.Path) (results []Result, unbudgetedPaths []files.Path) {
+	counts := make(map[string]uint, len(budgets))
+	for _, path := range invalidPaths {
+		prefix, ok := Prefix(dir, budgets, path)
+		if !ok {
+			unbudgetedPaths = append(unbudgetedPaths, path)
+			continue
+		}
+		counts[prefix]++
+	}
+
+	for prefix, max := range budgets {
+		resolved := Resolve(dir, prefix)
+		if slices.ContainsFunc(roots, func(root files.Path) bool { return root.Contains(resolved) }) {
+			results = append(results, Result{Prefix: prefix, Budget: max, Count: counts[prefix]})
+		}
+	}
+	slices.SortFunc(results, func(a, b Result) int {
+		return strings.Compare(a.Prefix, b.Prefix)
+	})
+	return
+}
+
+// Produces the most specific budgeted prefix containing a path, relative to a directory, if any.
+func Prefix(dir files.Path, budgets map[string]uint, path files.Path) (prefix string, ok bool) {
+	var longest files.Path
+	for candidate := range budgets {
+		resolved := Resolve(dir, candidate)
+		// Ties between prefixes resolving to the same directory are broken by name, so that the choice is stable.
+		if resolved.Contains(path) && (!ok || len(resolved) > len(longest) || len(resolved) == len(longest) && candidate < prefix) {
+			prefix, longest, ok = candidate, resolved, true
+		}
+	}
+	return
+}
+
+// Resolves a slash-separated directory prefix relative to a directory.
+func Resolve(dir files.Path, prefix string) files.Path {
+	prefix = path.Clean(prefix)
+	if prefix == "." {
+		return slices.Clone(dir)
+	}
+	return slices.Concat(dir, strings.Split(prefix, "/"))
+}
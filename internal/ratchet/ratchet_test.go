@@ -0,0 +1,70 @@
+package ratchet_test
+
+import (
+	"snekcheck/internal/files"
+	"snekcheck/internal/ratchet"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+	dir := files.NewPath("/repo")
+	invalidPaths := []files.Path{
+		files.NewPath("/repo/legacy/A"),
+		files.NewPath("/repo/legacy/B"),
+		files.NewPath("/repo/legacy/payments/C"),
+		files.NewPath("/repo/src/D"),
+	}
+	roots := []files.Path{dir}
+
+	t.Run("counts paths against the most specific prefix", func(t *testing.T) {
+		results, unbudgetedPaths := ratchet.Count(dir, map[string]uint{"legacy": 2, "legacy/payments/": 0}, roots, invalidPaths)
+		assert.Equal(t, []ratchet.Result{
+			{Prefix: "legacy", Budget: 2, Count: 2},
+			{Prefix: "legacy/payments/", Budget: 0, Count: 1},
+		}, results)
+		assert.Equal(t, []files.Path{files.NewPath("/repo/src/D")}, unbudgetedPaths)
+	})
+	t.Run("budgets the whole directory", func(t *testing.T) {
+		results, unbudgetedPaths := ratchet.Count(dir, map[string]uint{".": 10}, roots, invalidPaths)
+		assert.Equal(t, []ratchet.Result{{Prefix: ".", Budget: 10, Count: 4}}, results)
+		assert.Empty(t, unbudgetedPaths)
+	})
+	t.Run("does not match prefixes of names", func(t *testing.T) {
+		results, unbudgetedPaths := ratchet.Count(dir, map[string]uint{"leg": 10}, roots, invalidPaths)
+		assert.Equal(t, []ratchet.Result{{Prefix: "leg", Budget: 10}}, results)
+		assert.Len(t, unbudgetedPaths, 4)
+	})
+	t.Run("only produces the budgets of prefixes within the counted roots", func(t *testing.T) {
+		roots := []files.Path{files.NewPath("/repo/legacy/payments"), files.NewPath("/repo/src")}
+		results, unbudgetedPaths := ratchet.Count(dir, map[string]uint{".": 10, "legacy": 2, "legacy/payments": 1}, roots,
+			[]files.Path{files.NewPath("/repo/legacy/payments/C"), files.NewPath("/repo/src/D")})
+		assert.Equal(t, []ratchet.Result{{Prefix: "legacy/payments", Budget: 1, Count: 1}}, results)
+		assert.Empty(t, unbudgetedPaths)
+	})
+}
+
+func TestPrefix(t *testing.T) {
+	t.Parallel()
+	dir := files.NewPath("/repo")
+	budgets := map[string]uint{".": 10, "legacy": 2, "legacy/payments/": 0}
+
+	prefix, ok := ratchet.Prefix(dir, budgets, files.NewPath("/repo/legacy/payments/C"))
+	assert.True(t, ok)
+	assert.Equal(t, "legacy/payments/", prefix)
+	prefix, ok = ratchet.Prefix(dir, budgets, files.NewPath("/repo/src/D"))
+	assert.True(t, ok)
+	assert.Equal(t, ".", prefix)
+	_, ok = ratchet.Prefix(dir, map[string]uint{"legacy": 2}, files.NewPath("/repo/src/D"))
+	assert.False(t, ok)
+}
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+	assert.True(t, ratchet.Result{Budget: 1, Count: 2}.Exceeded())
+	assert.False(t, ratchet.Result{Budget: 2, Count: 2}.Exceeded())
+	assert.True(t, ratchet.Result{Budget: 2, Count: 1}.Lowerable())
+	assert.False(t, ratchet.Result{Budget: 2, Count: 2}.Lowerable())
+}
@@ -0,0 +1,225 @@
+// Package repo inspects the objects of Git repositories.
+package repo
+
+import (
+	"fmt"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// A commit that changed the set of invalid names in a repository.
+type Commit struct {
+	Hash   plumbing.Hash
+	Author object.Signature
+}
+
+// A slash-separated path with an invalid name, annotated with the commits that introduced and removed it.
+type Violation struct {
+	Path string
+	// The commit that introduced the path. Nil if the path predates the audited range.
+	Introduced *Commit
+	// The commit that removed or renamed the path. Nil if the path still exists.
+	Fixed *Commit
+}
+
+// The result of auditing a range of commits.
+type Audit struct {
+	// Paths that are invalid at the end of the range, sorted by path.
+	Current []Violation
+	// Paths that were invalid at some point in the range but no longer exist, in the order they were fixed.
+	Fixed []Violation
+}
+
+// The number of invalid names introduced and fixed in a single month.
+type Period struct {
+	Month      time.Time
+	Introduced uint
+	Fixed      uint
+}
+
+// Summarizes an audit as the number of invalid names introduced and fixed per month, in chronological order.
+func (a Audit) Trend() []Period {
+	counts := make(map[time.Time]*Period)
+	period := func(when time.Time) *Period {
+		when = when.UTC()
+		month := time.Date(when.Year(), when.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if counts[month] == nil {
+			counts[month] = &Period{Month: month}
+		}
+		return counts[month]
+	}
+
+	for _, violation := range slices.Concat(a.Current, a.Fixed) {
+		if violation.Introduced != nil {
+			period(violation.Introduced.Author.When).Introduced += 1
+		}
+		if violation.Fixed != nil {
+			period(violation.Fixed.Author.When).Fixed += 1
+		}
+	}
+
+	trend := make([]Period, 0, len(counts))
+	for _, p := range counts {
+		trend = append(trend, *p)
+	}
+	slices.SortFunc(trend, func(a, b Period) int { return a.Month.Compare(b.Month) })
+	return trend
+}
+
+// Audits the commits after from, up to and including to, reporting when each invalid name was introduced and fixed.
+// An empty from audits every commit reachable from to.
+// History is followed along first parents, so changes merged from other branches are attributed to the merge commit.
+func History(r *git.Repository, from string, to string, isValid func(name string) bool) (audit Audit, err error) {
+	if r == nil {
+		panic("invalid repository")
+	}
+
+	commits, startTree, walkErr := firstParentRange(r, from, to)
+	if walkErr != nil {
+		return Audit{}, walkErr
+	}
+
+	t := tracker{counts: make(map[string]uint), open: make(map[string]*Violation), isValid: isValid}
+	if startTree != nil {
+		walkErr = startTree.Files().ForEach(func(f *object.File) error {
+			t.add(f.Name, nil)
+			return nil
+		})
+		if walkErr != nil {
+			return Audit{}, fmt.Errorf("failed to read tree: %w", walkErr)
+		}
+	}
+
+	parentTree := startTree
+	for _, c := range commits {
+		tree, treeErr := c.Tree()
+		if treeErr != nil {
+			return Audit{}, fmt.Errorf("failed to read tree of commit %s: %w", c.Hash, treeErr)
+		}
+		changes, diffErr := object.DiffTree(parentTree, tree)
+		if diffErr != nil {
+			return Audit{}, fmt.Errorf("failed to diff commit %s: %w", c.Hash, diffErr)
+		}
+
+		// Apply insertions before deletions so that moves within a directory do not close and reopen it.
+		commit := &Commit{Hash: c.Hash, Author: c.Author}
+		for _, change := range changes {
+			if action, _ := change.Action(); action == merkletrie.Insert {
+				t.add(change.To.Name, commit)
+			}
+		}
+		for _, change := range changes {
+			if action, _ := change.Action(); action == merkletrie.Delete {
+				t.remove(change.From.Name, commit)
+			}
+		}
+		parentTree = tree
+	}
+
+	audit.Fixed = t.fixed
+	audit.Current = make([]Violation, 0, len(t.open))
+	for _, violation := range t.open {
+		audit.Current = append(audit.Current, *violation)
+	}
+	slices.SortFunc(audit.Current, func(a, b Violation) int { return strings.Compare(a.Path, b.Path) })
+	return audit, nil
+}
+
+// Resolves the first-parent chain of commits after from, up to and including to, in chronological order.
+// Also produces the tree of from, or nil if from is empty.
+func firstParentRange(r *git.Repository, from string, to string) (commits []*object.Commit, startTree *object.Tree, err error) {
+	toHash, resolveErr := r.ResolveRevision(plumbing.Revision(to))
+	if resolveErr != nil {
+		return nil, nil, fmt.Errorf("unknown revision %s: %w", to, resolveErr)
+	}
+	var fromHash plumbing.Hash
+	if from != "" {
+		resolved, resolveErr := r.ResolveRevision(plumbing.Revision(from))
+		if resolveErr != nil {
+			return nil, nil, fmt.Errorf("unknown revision %s: %w", from, resolveErr)
+		}
+		fromHash = *resolved
+	}
+
+	commit, commitErr := r.CommitObject(*toHash)
+	for commitErr == nil && commit.Hash != fromHash {
+		commits = append(commits, commit)
+		if commit.NumParents() == 0 {
+			break
+		}
+		commit, commitErr = commit.Parent(0)
+	}
+	if commitErr != nil {
+		return nil, nil, fmt.Errorf("failed to read commit: %w", commitErr)
+	}
+
+	if !fromHash.IsZero() {
+		if commit.Hash != fromHash {
+			return nil, nil, fmt.Errorf("%s is not a first-parent ancestor of %s", from, to)
+		}
+		startTree, err = commit.Tree()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read tree of commit %s: %w", commit.Hash, err)
+		}
+	}
+
+	slices.Reverse(commits)
+	return commits, startTree, nil
+}
+
+// Tracks which paths exist and which invalid paths are open while replaying history.
+type tracker struct {
+	// The number of files at or beneath each path.
+	counts map[string]uint
+	// Invalid paths that currently exist.
+	open map[string]*Violation
+	// Invalid paths that no longer exist.
+	fixed   []Violation
+	isValid func(name string) bool
+}
+
+// Records a file being added, opening a violation for each newly created invalid path element.
+func (t *tracker) add(file string, commit *Commit) {
+	for p := range prefixes(file) {
+		t.counts[p] += 1
+		if t.counts[p] == 1 && !t.isValid(path.Base(p)) {
+			t.open[p] = &Violation{Path: p, Introduced: commit}
+		}
+	}
+}
+
+// Records a file being removed, fixing each invalid path element that no longer exists.
+func (t *tracker) remove(file string, commit *Commit) {
+	for p := range prefixes(file) {
+		if t.counts[p] > 1 {
+			t.counts[p] -= 1
+			continue
+		}
+		delete(t.counts, p)
+		if violation, ok := t.open[p]; ok {
+			violation.Fixed = commit
+			t.fixed = append(t.fixed, *violation)
+			delete(t.open, p)
+		}
+	}
+}
+
+// Iterates over every leading portion of a slash-separated path, from shortest to longest.
+func prefixes(file string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i, r := range file {
+			if r == '/' && !yield(file[:i]) {
+				return
+			}
+		}
+		yield(file)
+	}
+}
@@ -0,0 +1,135 @@
+package repo_test
+
+import (
+	"snekcheck/internal/repo"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Determines if a name is lowercase, standing in for snekcheck's validator.
+func isLower(name string) bool {
+	return strings.ToLower(name) == name
+}
+
+// Creates an empty in-memory repository.
+func initRepo(t *testing.T) *git.Repository {
+	r, initErr := git.Init(memory.NewStorage(), memfs.New())
+	require.Nil(t, initErr)
+	return r
+}
+
+// Applies file creations and removals to a repository's worktree and commits them.
+func commit(t *testing.T, r *git.Repository, author string, when time.Time, create []string, remove []string) plumbing.Hash {
+	worktree, worktreeErr := r.Worktree()
+	require.Nil(t, worktreeErr)
+	for _, name := range create {
+		require.Nil(t, util.WriteFile(worktree.Filesystem, name, []byte(name), 0o644))
+		_, addErr := worktree.Add(name)
+		require.Nil(t, addErr)
+	}
+	for _, name := range remove {
+		_, removeErr := worktree.Remove(name)
+		require.Nil(t, removeErr)
+	}
+	hash, commitErr := worktree.Commit(author, &git.CommitOptions{
+		Author:            &object.Signature{Name: author, Email: author + "@example.com", When: when},
+		AllowEmptyCommits: true,
+	})
+	require.Nil(t, commitErr)
+	return hash
+}
+
+func TestHistory(t *testing.T) {
+	january := time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2026, time.February, 10, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Parallel()
+	t.Run("reports the commit that introduced each current violation", func(t *testing.T) {
+		r := initRepo(t)
+		commit(t, r, "alice", january, []string{"good.txt"}, nil)
+		second := commit(t, r, "bob", february, []string{"Bad.txt"}, nil)
+		commit(t, r, "carol", march, []string{"also_good.txt"}, nil)
+
+		audit, historyErr := repo.History(r, "", "HEAD", isLower)
+		require.Nil(t, historyErr)
+		require.Len(t, audit.Current, 1)
+		assert.Equal(t, "Bad.txt", audit.Current[0].Path)
+		require.NotNil(t, audit.Current[0].Introduced)
+		assert.Equal(t, second, audit.Current[0].Introduced.Hash)
+		assert.Equal(t, "bob", audit.Current[0].Introduced.Author.Name)
+		assert.Nil(t, audit.Current[0].Fixed)
+		assert.Empty(t, audit.Fixed)
+	})
+	t.Run("reports invalid directories once", func(t *testing.T) {
+		r := initRepo(t)
+		first := commit(t, r, "alice", january, []string{"Dir/a.txt"}, nil)
+		commit(t, r, "bob", february, []string{"Dir/b.txt"}, []string{"Dir/a.txt"})
+
+		audit, historyErr := repo.History(r, "", "HEAD", isLower)
+		require.Nil(t, historyErr)
+		require.Len(t, audit.Current, 1)
+		assert.Equal(t, "Dir", audit.Current[0].Path)
+		assert.Equal(t, first, audit.Current[0].Introduced.Hash)
+		assert.Empty(t, audit.Fixed)
+	})
+	t.Run("reports violations that were later fixed", func(t *testing.T) {
+		r := initRepo(t)
+		first := commit(t, r, "alice", january, []string{"Bad.txt"}, nil)
+		second := commit(t, r, "bob", february, []string{"bad.txt"}, []string{"Bad.txt"})
+
+		audit, historyErr := repo.History(r, "", "HEAD", isLower)
+		require.Nil(t, historyErr)
+		assert.Empty(t, audit.Current)
+		require.Len(t, audit.Fixed, 1)
+		assert.Equal(t, "Bad.txt", audit.Fixed[0].Path)
+		assert.Equal(t, first, audit.Fixed[0].Introduced.Hash)
+		assert.Equal(t, second, audit.Fixed[0].Fixed.Hash)
+	})
+	t.Run("attributes violations before the range to no commit", func(t *testing.T) {
+		r := initRepo(t)
+		first := commit(t, r, "alice", january, []string{"Old.txt"}, nil)
+		commit(t, r, "bob", february, []string{"New.txt"}, nil)
+
+		audit, historyErr := repo.History(r, first.String(), "HEAD", isLower)
+		require.Nil(t, historyErr)
+		require.Len(t, audit.Current, 2)
+		assert.Equal(t, "New.txt", audit.Current[0].Path)
+		assert.NotNil(t, audit.Current[0].Introduced)
+		assert.Equal(t, "Old.txt", audit.Current[1].Path)
+		assert.Nil(t, audit.Current[1].Introduced)
+	})
+	t.Run("summarizes violations per month", func(t *testing.T) {
+		r := initRepo(t)
+		commit(t, r, "alice", january, []string{"A.txt", "B.txt"}, nil)
+		commit(t, r, "bob", march, []string{"a.txt"}, []string{"A.txt"})
+
+		audit, historyErr := repo.History(r, "", "HEAD", isLower)
+		require.Nil(t, historyErr)
+		trend := audit.Trend()
+		require.Len(t, trend, 2)
+		assert.Equal(t, time.January, trend[0].Month.Month())
+		assert.EqualValues(t, 2, trend[0].Introduced)
+		assert.EqualValues(t, 0, trend[0].Fixed)
+		assert.Equal(t, time.March, trend[1].Month.Month())
+		assert.EqualValues(t, 0, trend[1].Introduced)
+		assert.EqualValues(t, 1, trend[1].Fixed)
+	})
+	t.Run("errors on unknown revisions", func(t *testing.T) {
+		r := initRepo(t)
+		commit(t, r, "alice", january, []string{"a.txt"}, nil)
+
+		_, historyErr := repo.History(r, "does-not-exist", "HEAD", isLower)
+		assert.NotNil(t, historyErr)
+	})
+}
@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Validates every slash-separated segment of a repository's branch and tag names.
+// References whose short name starts with an allowed prefix are skipped.
+func Refs(r *git.Repository, allow []string, isValid func(name string) bool) (validRefs []string, invalidRefs []string, err error) {
+	if r == nil {
+		panic("invalid repository")
+	}
+
+	references, referencesErr := r.References()
+	if referencesErr != nil {
+		return nil, nil, fmt.Errorf("failed to list references: %w", referencesErr)
+	}
+
+	iterErr := references.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsBranch() && !ref.Name().IsTag() {
+			return nil
+		}
+		name := ref.Name().Short()
+		if slices.ContainsFunc(allow, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+			return nil
+		}
+
+		if slices.ContainsFunc(strings.Split(name, "/"), func(segment string) bool { return !isValid(segment) }) {
+			invalidRefs = append(invalidRefs, name)
+		} else {
+			validRefs = append(validRefs, name)
+		}
+		return nil
+	})
+	if iterErr != nil {
+		return nil, nil, fmt.Errorf("failed to list references: %w", iterErr)
+	}
+
+	slices.Sort(validRefs)
+	slices.Sort(invalidRefs)
+	return validRefs, invalidRefs, nil
+}
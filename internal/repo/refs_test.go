@@ -0,0 +1,58 @@
+package repo_test
+
+import (
+	"snekcheck/internal/repo"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefs(t *testing.T) {
+	t.Parallel()
+	t.Run("validates each segment of branch and tag names", func(t *testing.T) {
+		r := initRepo(t)
+		head := commit(t, r, "alice", time.Now(), []string{"a.txt"}, nil)
+		for _, name := range []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName("feature/add_login"),
+			plumbing.NewBranchReferenceName("Feature/add_login"),
+			plumbing.NewBranchReferenceName("feature/AddLogin"),
+			plumbing.NewTagReferenceName("v1.2.3"),
+		} {
+			require.Nil(t, r.Storer.SetReference(plumbing.NewHashReference(name, head)))
+		}
+
+		validRefs, invalidRefs, refsErr := repo.Refs(r, nil, isLower)
+		require.Nil(t, refsErr)
+		assert.Equal(t, []string{"feature/add_login", "master", "v1.2.3"}, validRefs)
+		assert.Equal(t, []string{"Feature/add_login", "feature/AddLogin"}, invalidRefs)
+	})
+	t.Run("skips references with allowed prefixes", func(t *testing.T) {
+		r := initRepo(t)
+		head := commit(t, r, "alice", time.Now(), []string{"a.txt"}, nil)
+		for _, name := range []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName("release/2026-Q1"),
+			plumbing.NewTagReferenceName("v1.2.3-RC1"),
+			plumbing.NewTagReferenceName("Invalid"),
+		} {
+			require.Nil(t, r.Storer.SetReference(plumbing.NewHashReference(name, head)))
+		}
+
+		validRefs, invalidRefs, refsErr := repo.Refs(r, []string{"release/", "v"}, isLower)
+		require.Nil(t, refsErr)
+		assert.Equal(t, []string{"master"}, validRefs)
+		assert.Equal(t, []string{"Invalid"}, invalidRefs)
+	})
+	t.Run("ignores remote-tracking references", func(t *testing.T) {
+		r := initRepo(t)
+		head := commit(t, r, "alice", time.Now(), []string{"a.txt"}, nil)
+		name := plumbing.NewRemoteReferenceName("origin", "Bad")
+		require.Nil(t, r.Storer.SetReference(plumbing.NewHashReference(name, head)))
+
+		_, invalidRefs, refsErr := repo.Refs(r, nil, isLower)
+		require.Nil(t, refsErr)
+		assert.Empty(t, invalidRefs)
+	})
+}
@@ -0,0 +1,20 @@
+// Package testutil provides helpers shared by the tests of several packages.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/require"
+)
+
+// Initializes an in-memory filesystem with files and their contents.
+func InitFiles(t *testing.T, contents map[string]string) billy.Filesystem {
+	fs := memfs.New()
+	for name, content := range contents {
+		require.Nil(t, util.WriteFile(fs, name, []byte(content), 0o644))
+	}
+	return fs
+}
@@ -0,0 +1,685 @@
+// Package lint checks that filenames are snake_case, and renames them to be.
+//
+// A Linter is constructed from Options, which mirror snekcheck's CLI flags,
+// and reports its verdicts as structured Diagnostics rather than logging them.
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"snekcheck/internal/baseline"
+	"snekcheck/internal/cache"
+	"snekcheck/internal/config"
+	"snekcheck/internal/conventions"
+	"snekcheck/internal/files"
+	"snekcheck/internal/plugin"
+	"snekcheck/internal/ratchet"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// The ways Git submodules can be handled.
+const (
+	// Walks submodules with their own ignore rules and configuration.
+	SubmodulesRecurse = config.SubmodulesRecurse
+	// Excludes submodules entirely.
+	SubmodulesSkip = config.SubmodulesSkip
+)
+
+// Options for constructing a Linter.
+// Options override the configuration files of the directory and of any nested repositories.
+type Options struct {
+	// The filesystem containing the linted paths. Required.
+	FileSystem billy.Filesystem
+	// The absolute directory whose configuration file is loaded, which relative paths and glob patterns are resolved against.
+	// Defaults to the root directory.
+	Dir string
+	// Doublestar glob patterns. When any are specified, only matching paths are validated.
+	Include []string
+	// Doublestar glob patterns, whose matching paths are skipped entirely.
+	Exclude []string
+	// Additional files with gitignore syntax, such as .dockerignore.
+	IgnoreFiles []string
+	// Whether Git's ignore sources are disregarded.
+	NoGitIgnore bool
+	// Whether common dependency and build directories, such as node_modules, are checked.
+	NoDefaultExcludes bool
+	// Whether symbolic links to directories are descended into.
+	FollowSymlinks bool
+	// The maximum number of directories read concurrently. Defaults to the configuration, or else the number of CPUs.
+	Jobs int
+	// How Git submodules are handled, either SubmodulesRecurse or SubmodulesSkip. Defaults to the configuration.
+	Submodules string
+	// Invalid paths accepted as existing violations, so that they are reported as Baselined rather than Invalid.
+	Baseline []string
+	// The file in which directory entries and verdicts are cached between checks. Caching is disabled when empty.
+	CacheFile string
+	// Whether the plugins of the configuration are run.
+	// Plugins run arbitrary commands, so configuration files that are not trusted must not run them.
+	Plugins bool
+}
+
+// Checks and fixes filenames according to snekcheck's opinionated validator.
+type Linter struct {
+	fs      billy.Filesystem
+	dir     files.Path
+	options Options
+	// The configuration of the directory, with options applied.
+	cfg      config.Config
+	baseline baseline.Baseline
+}
+
+// Constructs a new Linter.
+// Fails if the options are invalid, or if the configuration file of the directory cannot be loaded.
+func New(options Options) (*Linter, error) {
+	if options.FileSystem == nil {
+		return nil, errors.New("invalid filesystem")
+	}
+	if options.Dir == "" {
+		options.Dir = string(filepath.Separator)
+	}
+	if !filepath.IsAbs(options.Dir) {
+		return nil, fmt.Errorf("directory %s is not absolute", options.Dir)
+	}
+	if options.Submodules != "" && options.Submodules != SubmodulesRecurse && options.Submodules != SubmodulesSkip {
+		return nil, fmt.Errorf("invalid submodules mode: %s", options.Submodules)
+	}
+	if options.Jobs < 0 {
+		return nil, fmt.Errorf("invalid number of jobs: %d", options.Jobs)
+	}
+	for _, pattern := range slices.Concat(options.Include, options.Exclude) {
+		if !files.ValidGlob(pattern) {
+			return nil, fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+
+	l := &Linter{fs: options.FileSystem, dir: files.NewPath(filepath.Clean(options.Dir)), options: options}
+	cfg, configErr := l.loadConfig(l.dir)
+	if configErr != nil {
+		return nil, configErr
+	}
+	for _, pattern := range options.Include {
+		cfg.Include = append(cfg.Include, files.AbsGlob(l.dir, pattern))
+	}
+	for _, pattern := range options.Exclude {
+		cfg.Exclude = append(cfg.Exclude, files.AbsGlob(l.dir, pattern))
+	}
+	for _, path := range options.IgnoreFiles {
+		cfg.Ignore.Files = append(cfg.Ignore.Files, l.abs(path))
+	}
+	l.cfg = cfg
+
+	baselinePaths := make([]files.Path, len(options.Baseline))
+	for i, path := range options.Baseline {
+		baselinePaths[i] = files.NewPath(l.abs(path))
+	}
+	l.baseline = baseline.New(l.dir, baselinePaths)
+	return l, nil
+}
+
+// The kinds of diagnostics.
+type Kind string
+
+const (
+	// A valid name.
+	Valid Kind = "VALID"
+	// An invalid name.
+	Invalid Kind = "INVALID"
+	// An invalid name of a generated or vendored path, which does not fail.
+	Warning Kind = "WARNING"
+	// An invalid name accepted by the baseline.
+	Baselined Kind = "BASELINE"
+	// An invalid name within a ratchet budget that is not exceeded, which does not fail.
+	Budgeted Kind = "BUDGETED"
+	// A ratchet budget whose directory contains more invalid names than it allows, which fails.
+	Exceeded Kind = "EXCEEDED"
+	// A path accepted by the baseline that no longer exists.
+	Stale Kind = "STALE"
+	// An invalid name that has been renamed.
+	Fixed Kind = "FIXED"
+	// A time-boxed .snekcheckignore entry that has expired, which fails.
+	Expired Kind = "EXPIRED"
+	// A symbolic link whose target does not exist.
+	Dangling Kind = "DANGLING"
+)
+
+// A verdict about a single path.
+// Invalid names produce a diagnostic for each rule they violate.
+type Diagnostic struct {
+	Kind Kind
+	// The absolute path. For expired suppressions, the path of the .snekcheckignore file.
+	// For exceeded budgets, the budgeted directory.
+	Path string
+	// The ID of the rule violated by an invalid name.
+	Rule string
+	// Describes how an invalid name violates the rule.
+	Message string
+	// The filename that Fix renames an invalid path to, if it can be fixed.
+	// Plugins may suggest filenames, which are adopted before the name is corrected by the rules.
+	Fix string
+	// The new absolute path of a fixed path.
+	NewPath string
+	// The expired suppression.
+	Suppression *Suppression
+	// The exceeded budget.
+	Budget *Budget
+}
+
+// A time-boxed .snekcheckignore entry.
+type Suppression struct {
+	Pattern string
+	Until   time.Time
+	Owner   string
+}
+
+// The number of invalid names within a directory budgeted by the ratchet section of the configuration.
+type Budget = ratchet.Result
+
+// The diagnostics produced by checking or fixing paths, in the order the paths were walked.
+// When checking, they are followed by those of stale baseline paths and of exceeded budgets.
+type Result struct {
+	Diagnostics []Diagnostic
+	// Problems that did not stop the walk, such as ignore files that could not be loaded.
+	Warnings []error
+	// The absolute paths checked in full, namely the checked paths unless they are filtered by include or exclude patterns.
+	Roots []string
+	// The ratchet budgets of the directories within the roots, sorted by prefix.
+	Budgets []Budget
+}
+
+// Produces the paths of the diagnostics of a kind, once each.
+func (r Result) Paths(kind Kind) (paths []string) {
+	for _, d := range r.Diagnostics {
+		if d.Kind == kind && (len(paths) == 0 || paths[len(paths)-1] != d.Path) {
+			paths = append(paths, d.Path)
+		}
+	}
+	return
+}
+
+// Determines if any diagnostic fails, namely invalid names, expired suppressions and exceeded budgets.
+func (r Result) Failed() bool {
+	return slices.ContainsFunc(r.Diagnostics, func(d Diagnostic) bool {
+		return d.Kind == Invalid || d.Kind == Expired || d.Kind == Exceeded
+	})
+}
+
+// Checks paths against the enabled rules and the configured plugins, if allowed, recursively descending into directories.
+// Filenames mandated by the enabled ecosystems, such as Makefile, are valid.
+// Paths within nested repositories are checked according to the configuration of the nested repository.
+// Paths exempted by gitattributes, and files containing the ignore-name pragma, are skipped.
+// Invalid generated or vendored paths only produce warnings.
+// Invalid paths accepted by the baseline are reported, but are not considered invalid.
+// Neither are invalid paths within ratchet budgets that are not exceeded, though exceeded budgets are reported.
+// Only the budgets of directories within the paths are counted, and none are when the paths are filtered by include or exclude patterns.
+// Expired time-boxed .snekcheckignore entries, dangling symbolic links, and stale baseline paths within the paths are reported as well.
+// Fails if any path does not exist, if the configuration file of a nested repository cannot be loaded, or if any plugin fails.
+func (l *Linter) Check(paths ...string) (Result, error) {
+	roots, rootsErr := l.roots(paths)
+	if rootsErr != nil {
+		return Result{}, rootsErr
+	}
+
+	var r run
+	// Paths filtered by glob patterns from the options are not checked, so no budget is fully counted.
+	if len(l.options.Include) == 0 && len(l.options.Exclude) == 0 {
+		for _, root := range roots {
+			r.result.Roots = append(r.result.Roots, absString(root))
+		}
+	}
+	ps, startErr := l.startPlugins(&r)
+	if startErr != nil {
+		return Result{}, startErr
+	}
+	c := l.openCache(&r)
+	var batch []checked
+	for path, entry := range l.walk(l.cfg, c, roots, &r) {
+		e := checked{path: path, entry: entry, validated: entry.included && !entry.attributes.Exempt}
+		e.mandated = e.validated && entry.repository.allowlist.Contains(path.Base())
+		if e.validated && !e.mandated {
+			// Only valid verdicts are cached, since the rules are checked again to diagnose invalid names.
+			// The cache is keyed by the configuration of the directory, so nested repositories are always checked.
+			if valid, cached := c.Valid(path); entry.repository.nested || !cached || !valid {
+				e.violations = checkRules(entry.repository.rules, absString(path), entry.FileInfo)
+				if !entry.repository.nested {
+					c.SetValid(path, len(e.violations) == 0)
+				}
+			}
+		}
+		batch = append(batch, e)
+		if len(batch) == pluginBatchSize {
+			r.err = l.reportBatch(&r, ps, batch)
+			batch = batch[:0]
+			if r.err != nil {
+				break
+			}
+		}
+	}
+	// The paths walked before any failure are still reported.
+	if reportErr := l.reportBatch(&r, ps, batch); r.err == nil {
+		r.err = reportErr
+	}
+	if closeErr := ps.close(); r.err == nil {
+		r.err = closeErr
+	}
+	if r.err == nil {
+		for _, path := range l.baseline.Stale(l.fs) {
+			if slices.ContainsFunc(roots, func(root files.Path) bool { return root.Contains(path) }) {
+				r.report(Stale, path, Diagnostic{})
+			}
+		}
+		l.ratchet(&r)
+	}
+	if r.err == nil && l.options.CacheFile != "" {
+		if saveErr := c.Save(l.fs, files.NewPath(l.options.CacheFile)); saveErr != nil {
+			r.result.Warnings = append(r.result.Warnings, saveErr)
+		}
+	}
+	return r.result, r.err
+}
+
+// Counts the invalid names of a check against the ratchet budgets of the configuration, within the roots of its result.
+// Invalid names within budgets that are not exceeded are reported as Budgeted instead.
+func (l *Linter) ratchet(r *run) {
+	if len(l.cfg.Ratchet) == 0 {
+		return
+	}
+	var roots, invalidPaths []files.Path
+	for _, root := range r.result.Roots {
+		roots = append(roots, files.NewPath(root))
+	}
+	for _, path := range r.result.Paths(Invalid) {
+		invalidPaths = append(invalidPaths, files.NewPath(path))
+	}
+
+	budgets, _ := ratchet.Count(l.dir, l.cfg.Ratchet, roots, invalidPaths)
+	r.result.Budgets = budgets
+	exceeded := make(map[string]bool)
+	for _, b := range budgets {
+		if b.Exceeded() {
+			exceeded[b.Prefix] = true
+		}
+	}
+	for i, d := range r.result.Diagnostics {
+		if prefix, ok := ratchet.Prefix(l.dir, l.cfg.Ratchet, files.NewPath(d.Path)); d.Kind == Invalid && ok && !exceeded[prefix] {
+			r.result.Diagnostics[i].Kind = Budgeted
+		}
+	}
+	for _, b := range budgets {
+		if b.Exceeded() {
+			r.report(Exceeded, ratchet.Resolve(l.dir, b.Prefix), Diagnostic{Budget: &b})
+		}
+	}
+}
+
+// Lowers the budgets of the configuration file to the numbers of invalid names counted by a check, where they are lower.
+// Produces the lowered budgets, as counted.
+// Fails if the configuration file cannot be written.
+func (l *Linter) LowerRatchet(result Result) (lowered []Budget, err error) {
+	budgets := maps.Clone(l.cfg.Ratchet)
+	for _, b := range result.Budgets {
+		if b.Lowerable() {
+			budgets[b.Prefix] = b.Count
+			lowered = append(lowered, b)
+		}
+	}
+	if len(lowered) == 0 {
+		return nil, nil
+	}
+	if saveErr := config.SaveRatchet(l.fs, l.dir, budgets); saveErr != nil {
+		return nil, saveErr
+	}
+	return lowered, nil
+}
+
+// A path produced by walking a file tree, whose verdict awaits the plugins.
+type checked struct {
+	path  files.Path
+	entry entry
+	// Whether the name is validated, rather than excluded or exempt.
+	validated bool
+	// Whether the name is mandated by an enabled ecosystem, and so is valid regardless of any rule.
+	mandated bool
+	// The diagnostics of the built-in rules violated by the name.
+	violations []Diagnostic
+}
+
+// Checks a batch of walked paths with the plugins, and reports their diagnostics in order.
+// Fails if any plugin fails.
+func (l *Linter) reportBatch(r *run, ps plugins, batch []checked) error {
+	var paths []plugin.Path
+	for _, e := range batch {
+		if e.validated && !e.mandated {
+			paths = append(paths, pluginPath(e.path, e.entry))
+		}
+	}
+	pluginViolations, pluginErr := ps.check(paths)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
+	for _, e := range batch {
+		path, entry := e.path, e.entry
+		for _, s := range entry.expired {
+			r.report(Expired, s.File, Diagnostic{Suppression: &Suppression{Pattern: s.Pattern, Until: s.Until, Owner: s.Owner}})
+		}
+		if entry.dangling {
+			r.report(Dangling, path, Diagnostic{})
+		}
+		if !e.validated {
+			continue
+		}
+
+		violations := slices.Concat(e.violations, pluginViolations[absString(path)])
+		kind := Invalid
+		switch {
+		case len(violations) == 0:
+			r.report(Valid, path, Diagnostic{})
+			continue
+		case files.HasIgnoreNamePragma(l.fs, path, entry.FileInfo):
+			continue
+		case entry.attributes.Generated || entry.attributes.Vendored:
+			kind = Warning
+		case l.baseline.Contains(path):
+			kind = Baselined
+		}
+		// Generated and vendored paths are never renamed, so they are never fixable.
+		name, fixable := l.fixedName(path, entry.repository.rules, violations)
+		for _, d := range violations {
+			d.Fix = ""
+			if fixable && kind != Warning {
+				d.Fix = name
+			}
+			r.report(kind, path, d)
+		}
+	}
+	return nil
+}
+
+// Renames invalid paths to satisfy the enabled rules that can correct them, recursively descending into directories.
+// Filenames suggested by plugins are adopted first, and then corrected by the rules.
+// Invalid paths that cannot be corrected at all, or whose corrected name is already taken, are reported as such.
+// Paths exempted by gitattributes, or marked as generated or vendored, are never renamed.
+// Neither are filenames mandated by any ecosystem, even those disabled, nor files containing the ignore-name pragma.
+// Fails if any path does not exist or cannot be renamed, or if any plugin fails, though the paths renamed until then are reported.
+func (l *Linter) Fix(paths ...string) (Result, error) {
+	roots, rootsErr := l.roots(paths)
+	if rootsErr != nil {
+		return Result{}, rootsErr
+	}
+
+	var r run
+	ps, startErr := l.startPlugins(&r)
+	if startErr != nil {
+		return Result{}, startErr
+	}
+	fixErr := l.fix(ps, roots, &r)
+	return r.result, errors.Join(fixErr, ps.close())
+}
+
+// Renames invalid paths within file trees, checking each path with the plugins as it is walked.
+// Directories are renamed once the walk leaves them, after their entries, so that the walk of their entries,
+// and the ignore rules and configurations entered along the way, are not disrupted.
+// Until then, the paths within a directory that is renamed are reported at their new paths.
+func (l *Linter) fix(ps plugins, roots []files.Path, r *run) error {
+	allowlist := conventions.NewAllowlist(nil)
+	// The directories awaiting their renames, each within the previous one.
+	var pending []pendingRename
+	// Renames the pending directories that do not contain a path, or every pending directory, deepest first.
+	leave := func(path files.Path) error {
+		for len(pending) > 0 && (path == nil || !pending[len(pending)-1].path.Contains(path)) {
+			p := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			if renameErr := l.fs.Rename(p.path.String(), p.newPath.String()); renameErr != nil {
+				// The directory was reported as fixed, but remains invalid.
+				r.result.Diagnostics = slices.Replace(r.result.Diagnostics, p.reported, p.reported+1, p.invalid...)
+				return fmt.Errorf("unable to rename %s to %s: %w", p.path, p.newPath, renameErr)
+			}
+		}
+		return nil
+	}
+	// Produces the path that a walked path is reported at, once the pending directories containing it are renamed.
+	reported := func(path files.Path) files.Path {
+		if len(pending) == 0 {
+			return path
+		}
+		p := pending[len(pending)-1]
+		return slices.Concat(p.reportedNewPath, path[len(p.path):])
+	}
+
+	for path, entry := range l.walk(l.cfg, nil, roots, r) {
+		if leaveErr := leave(path); leaveErr != nil {
+			return leaveErr
+		}
+		if !entry.included || entry.attributes.Exempt || entry.attributes.Generated || entry.attributes.Vendored {
+			continue
+		}
+		violations := checkRules(entry.repository.rules, absString(path), entry.FileInfo)
+		if !allowlist.Contains(path.Base()) {
+			pluginViolations, pluginErr := ps.check([]plugin.Path{pluginPath(path, entry)})
+			if pluginErr != nil {
+				return errors.Join(pluginErr, leave(nil))
+			}
+			violations = append(violations, pluginViolations[absString(path)]...)
+		}
+		if len(violations) == 0 {
+			r.report(Valid, reported(path), Diagnostic{})
+			continue
+		}
+		if allowlist.Contains(path.Base()) || files.HasIgnoreNamePragma(l.fs, path, entry.FileInfo) {
+			continue
+		}
+
+		name, fixable := l.fixedName(path, entry.repository.rules, violations)
+		if !fixable {
+			for _, d := range violations {
+				d.Fix = ""
+				r.report(Invalid, reported(path), d)
+			}
+			continue
+		}
+
+		newPath := path.Parent().Join(name)
+		reportedNewPath := reported(newPath)
+		if entry.IsDir() {
+			p := pendingRename{path: path, newPath: newPath, reportedNewPath: reportedNewPath, reported: len(r.result.Diagnostics)}
+			for _, d := range violations {
+				d.Fix, d.Kind, d.Path = "", Invalid, absString(reported(path))
+				p.invalid = append(p.invalid, d)
+			}
+			r.report(Fixed, reported(path), Diagnostic{NewPath: absString(reportedNewPath)})
+			pending = append(pending, p)
+			continue
+		}
+		if renameErr := l.fs.Rename(path.String(), newPath.String()); renameErr != nil {
+			return errors.Join(fmt.Errorf("unable to rename %s to %s: %w", path, newPath, renameErr), leave(nil))
+		}
+		r.report(Fixed, reported(path), Diagnostic{NewPath: absString(reportedNewPath)})
+	}
+	// The directories walked before any failure are still renamed.
+	return errors.Join(r.err, leave(nil))
+}
+
+// A directory that is renamed by a fix once its entries are walked.
+type pendingRename struct {
+	path    files.Path
+	newPath files.Path
+	// The path that the directory is reported to be renamed to, once the pending directories containing it are renamed.
+	reportedNewPath files.Path
+	// The index of the diagnostic reporting the rename.
+	reported int
+	// The diagnostics that replace the reported rename, should it fail.
+	invalid []Diagnostic
+}
+
+// Produces the name that an invalid path is renamed to by Fix, given the diagnostics of its violations.
+// The first filename suggested by a plugin is adopted, and then corrected by the rules.
+// Names mandated by any ecosystem, names that cannot be corrected, and names that are already taken are not fixable.
+func (l *Linter) fixedName(path files.Path, rules []Rule, violations []Diagnostic) (name string, fixable bool) {
+	if conventions.NewAllowlist(nil).Contains(path.Base()) {
+		return "", false
+	}
+	name = path.Base()
+	if i := slices.IndexFunc(violations, func(d Diagnostic) bool { return d.Fix != "" }); i != -1 {
+		name = violations[i].Fix
+	}
+	name = fixName(rules, name)
+	if name == path.Base() || l.exists(path, path.Parent().Join(name)) {
+		return "", false
+	}
+	return name, true
+}
+
+// Determines if renaming a path would overwrite another existing path.
+// The same path with different casing, as found on case-insensitive filesystems, is not another path.
+func (l *Linter) exists(path files.Path, newPath files.Path) bool {
+	newInfo, newErr := l.fs.Lstat(newPath.String())
+	if newErr != nil {
+		return false
+	}
+	info, statErr := l.fs.Lstat(path.String())
+	return statErr != nil || !os.SameFile(info, newInfo)
+}
+
+// The state of a single check or fix.
+type run struct {
+	result Result
+	// The failure that stopped the walk, if any.
+	err error
+}
+
+// Reports a diagnostic about a path.
+func (r *run) report(kind Kind, path files.Path, d Diagnostic) {
+	d.Kind, d.Path = kind, absString(path)
+	r.result.Diagnostics = append(r.result.Diagnostics, d)
+}
+
+// Records a problem that does not stop the walk.
+func (r *run) warn(err error) {
+	r.result.Warnings = append(r.result.Warnings, err)
+}
+
+// Converts paths to absolute paths, resolving relative paths against the directory, as Check does.
+// Fails if any path does not exist.
+func (l *Linter) Roots(paths ...string) ([]string, error) {
+	roots, rootsErr := l.roots(paths)
+	if rootsErr != nil {
+		return nil, rootsErr
+	}
+	absRoots := make([]string, len(roots))
+	for i, root := range roots {
+		absRoots[i] = absString(root)
+	}
+	return absRoots, nil
+}
+
+// Converts paths to absolute paths, resolving relative paths against the directory.
+// Fails if any path does not exist.
+func (l *Linter) roots(paths []string) (roots []files.Path, err error) {
+	roots = make([]files.Path, len(paths))
+	for i, path := range paths {
+		absPath := l.abs(path)
+		if _, statErr := l.fs.Lstat(absPath); statErr != nil {
+			if errors.Is(statErr, fs.ErrNotExist) {
+				return nil, fmt.Errorf("no such file or directory: %s", path)
+			}
+			return nil, statErr
+		}
+		roots[i] = files.NewPath(absPath)
+	}
+	return roots, nil
+}
+
+// Resolves a path against the directory, unless it is absolute.
+func (l *Linter) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(absString(l.dir), path)
+}
+
+// Loads the configuration file in a directory, applying the options.
+// Fails if the configuration toggles an unknown rule.
+func (l *Linter) loadConfig(dir files.Path) (config.Config, error) {
+	cfg, configErr := config.Load(l.fs, dir)
+	if configErr != nil {
+		return config.Config{}, configErr
+	}
+	for id := range cfg.Rules {
+		if !IsRule(id) {
+			return config.Config{}, fmt.Errorf("invalid configuration file %s: unknown rule %q", dir.Join(config.FileName), id)
+		}
+	}
+	if l.options.Submodules != "" {
+		cfg.Submodules = l.options.Submodules
+	}
+	if l.options.NoGitIgnore {
+		cfg.Ignore.NoGitIgnore = true
+	}
+	if l.options.NoDefaultExcludes {
+		cfg.Ignore.NoDefaultExcludes = true
+	}
+	if l.options.FollowSymlinks {
+		cfg.FollowSymlinks = true
+	}
+	if l.options.Jobs != 0 {
+		cfg.Jobs = l.options.Jobs
+	}
+	return cfg, nil
+}
+
+// Opens the cache file, if any, for the configuration.
+// Produces an empty cache if it cannot be read, since the cache is only an optimization.
+func (l *Linter) openCache(r *run) *cache.Cache {
+	if l.options.CacheFile == "" {
+		return nil
+	}
+	c, openErr := cache.Open(l.fs, files.NewPath(l.options.CacheFile), l.cacheKey(), time.Now())
+	if openErr != nil {
+		r.warn(openErr)
+	}
+	return c
+}
+
+// Produces the cache key of the configuration, including the contents of its additional ignore files.
+func (l *Linter) cacheKey() string {
+	encoded, _ := json.Marshal(l.cfg)
+	parts := [][]byte{encoded, []byte(strconv.Itoa(conventions.Version))}
+	for _, path := range l.cfg.Ignore.Files {
+		contents, _ := util.ReadFile(l.fs, path)
+		parts = append(parts, contents)
+	}
+	return cache.Key(parts...)
+}
+
+// Converts a path to an absolute string.
+func absString(path files.Path) string {
+	return strings.Join(path, string(filepath.Separator))
+}
+
+// Produces the directories that are walked when checking paths, such as to watch them for changes.
+// Fails if any path does not exist, or if the configuration file of a nested repository cannot be loaded.
+func (l *Linter) Dirs(paths ...string) (dirs []string, err error) {
+	roots, rootsErr := l.roots(paths)
+	if rootsErr != nil {
+		return nil, rootsErr
+	}
+
+	var r run
+	for path, entry := range l.walk(l.cfg, nil, roots, &r) {
+		if entry.IsDir() {
+			dirs = append(dirs, absString(path))
+		}
+	}
+	return dirs, r.err
+}
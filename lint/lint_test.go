@@ -0,0 +1,531 @@
+package lint_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"snekcheck/internal/testutil"
+	"snekcheck/lint"
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Initializes a temporary directory with files and their contents, producing the OS filesystem and the directory.
+// Plugins require a directory that exists outside of memory to run in.
+func initDirFiles(t *testing.T, contents map[string]string) (billy.Filesystem, string) {
+	dir := t.TempDir()
+	fs := osfs.New("/")
+	for name, content := range contents {
+		require.Nil(t, util.WriteFile(fs, filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return fs, dir
+}
+
+// Builds the reference prefix plugin, producing the path of its executable.
+func buildPrefixPlugin(t *testing.T) string {
+	executable := filepath.Join(t.TempDir(), "prefix_plugin")
+	output, buildErr := exec.Command("go", "build", "-o", executable, "snekcheck/cmd/prefix_plugin").CombinedOutput()
+	require.Nil(t, buildErr, string(output))
+	return executable
+}
+
+// Produces the kinds of a result's diagnostics, keyed by path.
+func kinds(result lint.Result) map[string]lint.Kind {
+	kinds := make(map[string]lint.Kind)
+	for _, d := range result.Diagnostics {
+		kinds[d.Path] = d.Kind
+	}
+	return kinds
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	t.Run("fails without a filesystem", func(t *testing.T) {
+		_, newErr := lint.New(lint.Options{})
+		assert.NotNil(t, newErr)
+	})
+	t.Run("fails with invalid options", func(t *testing.T) {
+		testCases := map[string]lint.Options{
+			"relative directory": {Dir: "repo"},
+			"negative jobs":      {Jobs: -1},
+			"submodules mode":    {Submodules: "sometimes"},
+			"glob pattern":       {Include: []string{"[unclosed"}},
+		}
+		for name, options := range testCases {
+			t.Run(name, func(t *testing.T) {
+				options.FileSystem = memfs.New()
+				_, newErr := lint.New(options)
+				assert.NotNil(t, newErr)
+			})
+		}
+	})
+	t.Run("fails with an invalid configuration file", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{"/repo/.snekcheck.yaml": "unknown: true\n"})
+		_, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		assert.ErrorContains(t, newErr, "/repo/.snekcheck.yaml")
+	})
+	t.Run("fails with an unknown rule", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{"/repo/.snekcheck.yaml": "rules:\n  kebab: true\n"})
+		_, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		assert.ErrorContains(t, newErr, "/repo/.snekcheck.yaml")
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	t.Run("reports diagnostics in walk order", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.git/HEAD":    "",
+			"/repo/Bad.GO":       "",
+			"/repo/good.go":      "",
+			"/repo/Makefile":     "",
+			"/repo/dir/Other.go": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check(".")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Valid, Path: "/repo"},
+			{Kind: lint.Invalid, Path: "/repo/Bad.GO", Rule: "case", Message: `"Bad" is neither snake_case nor SCREAMING_SNAKE_CASE`, Fix: "bad.go"},
+			{Kind: lint.Invalid, Path: "/repo/Bad.GO", Rule: "extension", Message: `extension ".GO" is not snake_case`, Fix: "bad.go"},
+			{Kind: lint.Valid, Path: "/repo/Makefile"},
+			{Kind: lint.Valid, Path: "/repo/dir"},
+			{Kind: lint.Invalid, Path: "/repo/dir/Other.go", Rule: "case", Message: `"Other" is neither snake_case nor SCREAMING_SNAKE_CASE`, Fix: "other.go"},
+			{Kind: lint.Valid, Path: "/repo/good.go"},
+		}, result.Diagnostics)
+		assert.Equal(t, []string{"/repo/Bad.GO", "/repo/dir/Other.go"}, result.Paths(lint.Invalid))
+		assert.True(t, result.Failed())
+	})
+	t.Run("applies options", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/Legacy.go":     "",
+			"/repo/src/New.go":    "",
+			"/repo/test/Case.txt": "",
+		})
+		linter, newErr := lint.New(lint.Options{
+			FileSystem: fs,
+			Dir:        "/repo",
+			Exclude:    []string{"test/**"},
+			Baseline:   []string{"Legacy.go"},
+		})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("/repo")
+		require.Nil(t, checkErr)
+		assert.Equal(t, map[string]lint.Kind{
+			"/repo":            lint.Valid,
+			"/repo/Legacy.go":  lint.Baselined,
+			"/repo/src":        lint.Valid,
+			"/repo/src/New.go": lint.Invalid,
+		}, kinds(result))
+	})
+	t.Run("only checks enabled rules", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.snekcheck.yaml": "rules:\n  case: false\n",
+			"/repo/Bad.go":          "",
+			"/repo/Bad.GO":          "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("Bad.go", "Bad.GO")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Valid, Path: "/repo/Bad.go"},
+			{Kind: lint.Invalid, Path: "/repo/Bad.GO", Rule: "extension", Message: `extension ".GO" is not snake_case`},
+		}, result.Diagnostics)
+	})
+	t.Run("merges the diagnostics of plugins", func(t *testing.T) {
+		fs, dir := initDirFiles(t, map[string]string{
+			".snekcheck.yaml":    fmt.Sprintf("plugins:\n  - name: prefix\n    command: [%s, payments_]\n", buildPrefixPlugin(t)),
+			"Makefile":           "",
+			"Other.go":           "",
+			"payments_ledger.go": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: dir, Plugins: true})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("Makefile", "Other.go", "payments_ledger.go")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Valid, Path: filepath.Join(dir, "Makefile")},
+			{Kind: lint.Invalid, Path: filepath.Join(dir, "Other.go"), Rule: "case", Message: `"Other" is neither snake_case nor SCREAMING_SNAKE_CASE`, Fix: "payments_other.go"},
+			{Kind: lint.Invalid, Path: filepath.Join(dir, "Other.go"), Rule: "prefix/required", Message: `"Other.go" does not start with any of payments_`, Fix: "payments_other.go"},
+			{Kind: lint.Valid, Path: filepath.Join(dir, "payments_ledger.go")},
+		}, result.Diagnostics)
+	})
+	t.Run("skips plugins unless allowed", func(t *testing.T) {
+		fs, dir := initDirFiles(t, map[string]string{
+			".snekcheck.yaml": fmt.Sprintf("plugins:\n  - name: prefix\n    command: [%s, payments_]\n", buildPrefixPlugin(t)),
+			"Other.go":        "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: dir})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("Other.go")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Invalid, Path: filepath.Join(dir, "Other.go"), Rule: "case", Message: `"Other" is neither snake_case nor SCREAMING_SNAKE_CASE`, Fix: "other.go"},
+		}, result.Diagnostics)
+		assert.Len(t, result.Warnings, 1)
+	})
+	t.Run("fails if a plugin fails", func(t *testing.T) {
+		fs, dir := initDirFiles(t, map[string]string{
+			".snekcheck.yaml": fmt.Sprintf("plugins:\n  - name: prefix\n    command: [%s]\n", buildPrefixPlugin(t)),
+			"file.go":         "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: dir, Plugins: true})
+		require.Nil(t, newErr)
+
+		_, checkErr := linter.Check("file.go")
+		assert.NotNil(t, checkErr)
+	})
+	t.Run("reports expired suppressions", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.snekcheckignore": "Old.go until 2020-01-01 owner:@me\n",
+			"/repo/Old.go":           "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check(".")
+		require.Nil(t, checkErr)
+		require.Equal(t, lint.Expired, result.Diagnostics[0].Kind)
+		assert.Equal(t, "/repo/.snekcheckignore", result.Diagnostics[0].Path)
+		assert.Equal(t, "Old.go", result.Diagnostics[0].Suppression.Pattern)
+		assert.Equal(t, "@me", result.Diagnostics[0].Suppression.Owner)
+		assert.True(t, result.Failed())
+	})
+	t.Run("checks within paths that are symbolic links to directories", func(t *testing.T) {
+		fs, dir := initDirFiles(t, map[string]string{"real/BadFile": ""})
+		require.Nil(t, os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link")))
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: dir})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("link")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []string{filepath.Join(dir, "link", "BadFile")}, result.Paths(lint.Invalid))
+		assert.True(t, result.Failed())
+	})
+	t.Run("applies the ignore files of the directories enclosing checked paths", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.snekcheckignore": "Keep*\n",
+			"/repo/sub/KeepMe":       "",
+			"/repo/sub/Other":        "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("sub/KeepMe", "sub/Other")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []string{"/repo/sub/Other"}, result.Paths(lint.Invalid))
+		assert.Empty(t, result.Paths(lint.Valid))
+	})
+	t.Run("fails for paths that do not exist", func(t *testing.T) {
+		linter, newErr := lint.New(lint.Options{FileSystem: memfs.New()})
+		require.Nil(t, newErr)
+
+		_, checkErr := linter.Check("/missing")
+		assert.NotNil(t, checkErr)
+	})
+	t.Run("applies the configuration of nested repositories within them", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.git/HEAD":              "",
+			"/repo/Bad.go":                 "",
+			"/repo/nested/.git/HEAD":       "",
+			"/repo/nested/.snekcheck.yaml": "rules:\n  case: false\nexclude: [generated/**]\n",
+			"/repo/nested/Bad.go":          "",
+			"/repo/nested/generated/X.go":  "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check(".")
+		require.Nil(t, checkErr)
+		assert.Equal(t, map[string]lint.Kind{
+			"/repo":                        lint.Valid,
+			"/repo/Bad.go":                 lint.Invalid,
+			"/repo/nested":                 lint.Valid,
+			"/repo/nested/.snekcheck.yaml": lint.Valid,
+			"/repo/nested/Bad.go":          lint.Valid,
+		}, kinds(result))
+	})
+	t.Run("fails with an invalid configuration file in a nested repository", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.git/HEAD":                 "",
+			"/repo/nested/.git/HEAD":          "",
+			"/repo/nested/.snekcheck.yaml":    "jobs: -1\n",
+			"/repo/nested/valid_file_name.go": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		_, checkErr := linter.Check(".")
+		assert.NotNil(t, checkErr)
+	})
+	t.Run("counts invalid names against ratchet budgets", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.snekcheck.yaml":     "ratchet:\n  legacy: 1\n  old: 3\n",
+			"/repo/legacy/Ab.go":        "",
+			"/repo/legacy/Bc.go":        "",
+			"/repo/old/Cd.go":           "",
+			"/repo/other/valid_name.go": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check(".")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []string{"/repo/legacy/Ab.go", "/repo/legacy/Bc.go"}, result.Paths(lint.Invalid))
+		assert.Equal(t, []string{"/repo/old/Cd.go"}, result.Paths(lint.Budgeted))
+		assert.Equal(t, []lint.Budget{
+			{Prefix: "legacy", Budget: 1, Count: 2},
+			{Prefix: "old", Budget: 3, Count: 1},
+		}, result.Budgets)
+		exceeded := result.Diagnostics[len(result.Diagnostics)-1]
+		assert.Equal(t, lint.Exceeded, exceeded.Kind)
+		assert.Equal(t, "/repo/legacy", exceeded.Path)
+		assert.Equal(t, &lint.Budget{Prefix: "legacy", Budget: 1, Count: 2}, exceeded.Budget)
+		assert.True(t, result.Failed())
+
+		result, checkErr = linter.Check("old")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []lint.Budget{{Prefix: "old", Budget: 3, Count: 1}}, result.Budgets)
+		assert.False(t, result.Failed())
+	})
+	t.Run("only counts the budgets of directories within unfiltered paths", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.snekcheck.yaml": "ratchet:\n  legacy: 2\n",
+			"/repo/legacy/Ab.go":    "",
+			"/repo/legacy/Bc.go":    "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("legacy/Ab.go")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []string{"/repo/legacy/Ab.go"}, result.Roots)
+		assert.Empty(t, result.Budgets)
+		assert.Equal(t, []string{"/repo/legacy/Ab.go"}, result.Paths(lint.Budgeted))
+		assert.False(t, result.Failed())
+
+		filtered, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo", Exclude: []string{"legacy/Bc.go"}})
+		require.Nil(t, newErr)
+		result, checkErr = filtered.Check(".")
+		require.Nil(t, checkErr)
+		assert.Empty(t, result.Roots)
+		assert.Empty(t, result.Budgets)
+	})
+	t.Run("reports stale baseline paths within the checked paths", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/src/Bad.go":   "",
+			"/repo/other/Bad.go": "",
+		})
+		linter, newErr := lint.New(lint.Options{
+			FileSystem: fs,
+			Dir:        "/repo",
+			Baseline:   []string{"src/Bad.go", "src/Gone.go", "other/Gone.go"},
+		})
+		require.Nil(t, newErr)
+
+		result, checkErr := linter.Check("src")
+		require.Nil(t, checkErr)
+		assert.Equal(t, []string{"/repo/src/Gone.go"}, result.Paths(lint.Stale))
+		assert.False(t, result.Failed())
+	})
+}
+
+func TestLowerRatchet(t *testing.T) {
+	t.Parallel()
+	fs := testutil.InitFiles(t, map[string]string{
+		"/repo/.snekcheck.yaml": "ratchet:\n  legacy: 3\n  old: 1\n",
+		"/repo/legacy/Ab.go":    "",
+		"/repo/old/Bc.go":       "",
+	})
+	linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+	require.Nil(t, newErr)
+
+	result, checkErr := linter.Check(".")
+	require.Nil(t, checkErr)
+	lowered, lowerErr := linter.LowerRatchet(result)
+	require.Nil(t, lowerErr)
+	assert.Equal(t, []lint.Budget{{Prefix: "legacy", Budget: 3, Count: 1}}, lowered)
+
+	relinted, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+	require.Nil(t, newErr)
+	result, checkErr = relinted.Check(".")
+	require.Nil(t, checkErr)
+	assert.Equal(t, []lint.Budget{
+		{Prefix: "legacy", Budget: 1, Count: 1},
+		{Prefix: "old", Budget: 1, Count: 1},
+	}, result.Budgets)
+}
+
+func TestRoots(t *testing.T) {
+	t.Parallel()
+	fs := testutil.InitFiles(t, map[string]string{"/repo/src/file.go": ""})
+	linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+	require.Nil(t, newErr)
+
+	roots, rootsErr := linter.Roots("src", "/repo/src/file.go")
+	require.Nil(t, rootsErr)
+	assert.Equal(t, []string{"/repo/src", "/repo/src/file.go"}, roots)
+
+	_, rootsErr = linter.Roots("missing")
+	assert.EqualError(t, rootsErr, "no such file or directory: missing")
+}
+
+func TestFix(t *testing.T) {
+	t.Parallel()
+	t.Run("renames invalid paths", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/Bad Dir/file.go": "",
+			"/repo/Makefile":        "",
+			"/repo/src/Bad.go":      "",
+			"/repo/src/README.MD":   "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix(".")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Valid, Path: "/repo"},
+			{Kind: lint.Fixed, Path: "/repo/Bad Dir", NewPath: "/repo/bad_dir"},
+			{Kind: lint.Valid, Path: "/repo/bad_dir/file.go"},
+			{Kind: lint.Valid, Path: "/repo/src"},
+			{Kind: lint.Fixed, Path: "/repo/src/Bad.go", NewPath: "/repo/src/bad.go"},
+			{Kind: lint.Fixed, Path: "/repo/src/README.MD", NewPath: "/repo/src/README.md"},
+		}, result.Diagnostics)
+		_, statErr := fs.Stat("/repo/bad_dir/file.go")
+		assert.Nil(t, statErr)
+		_, statErr = fs.Stat("/repo/src/bad.go")
+		assert.Nil(t, statErr)
+		_, statErr = fs.Stat("/repo/Makefile")
+		assert.Nil(t, statErr)
+	})
+
+	t.Run("renames the entries of renamed directories", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/Foo/Bar.txt":     "",
+			"/repo/Foo/Baz/Qux.txt": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix("Foo")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Fixed, Path: "/repo/Foo", NewPath: "/repo/foo"},
+			{Kind: lint.Fixed, Path: "/repo/foo/Bar.txt", NewPath: "/repo/foo/bar.txt"},
+			{Kind: lint.Fixed, Path: "/repo/foo/Baz", NewPath: "/repo/foo/baz"},
+			{Kind: lint.Fixed, Path: "/repo/foo/baz/Qux.txt", NewPath: "/repo/foo/baz/qux.txt"},
+		}, result.Diagnostics)
+		_, statErr := fs.Stat("/repo/foo/bar.txt")
+		assert.Nil(t, statErr)
+		_, statErr = fs.Stat("/repo/foo/baz/qux.txt")
+		assert.Nil(t, statErr)
+	})
+	t.Run("applies the configuration of nested repositories within renamed directories", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.git/HEAD":           "",
+			"/repo/sub/.git/HEAD":       "",
+			"/repo/sub/.snekcheck.yaml": "rules:\n  case: false\n",
+			"/repo/sub/My Dir/Inner":    "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix(".")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []string{"/repo/sub/My Dir"}, result.Paths(lint.Fixed))
+		_, statErr := fs.Stat("/repo/sub/My_Dir/Inner")
+		assert.Nil(t, statErr)
+	})
+	t.Run("applies ignore files to the entries of renamed directories", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.snekcheckignore": "Keep*\n",
+			"/repo/My Dir/KeepThis":  "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix(".")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []string{"/repo/My Dir"}, result.Paths(lint.Fixed))
+		_, statErr := fs.Stat("/repo/my_dir/KeepThis")
+		assert.Nil(t, statErr)
+	})
+	t.Run("reports names that cannot be fixed", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/日本":     "",
+			"/repo/Bad.go": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix("日本", "Bad.go")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []string{"/repo/日本"}, result.Paths(lint.Invalid))
+		assert.Equal(t, []string{"/repo/Bad.go"}, result.Paths(lint.Fixed))
+		_, statErr := fs.Stat("/repo/日本")
+		assert.Nil(t, statErr)
+	})
+	t.Run("does not overwrite existing paths", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/Foo.txt": "new",
+			"/repo/foo.txt": "old",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix("Foo.txt")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []string{"/repo/Foo.txt"}, result.Paths(lint.Invalid))
+		contents, readErr := util.ReadFile(fs, "/repo/foo.txt")
+		require.Nil(t, readErr)
+		assert.Equal(t, "old", string(contents))
+	})
+	t.Run("adopts the fixes suggested by plugins", func(t *testing.T) {
+		fs, dir := initDirFiles(t, map[string]string{
+			".snekcheck.yaml": fmt.Sprintf("plugins:\n  - name: prefix\n    command: [%s, payments_]\n", buildPrefixPlugin(t)),
+			"Other.go":        "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: dir, Plugins: true})
+		require.Nil(t, newErr)
+
+		result, fixErr := linter.Fix("Other.go")
+		require.Nil(t, fixErr)
+		assert.Equal(t, []lint.Diagnostic{
+			{Kind: lint.Fixed, Path: filepath.Join(dir, "Other.go"), NewPath: filepath.Join(dir, "payments_other.go")},
+		}, result.Diagnostics)
+	})
+}
+
+func TestDirs(t *testing.T) {
+	t.Parallel()
+	t.Run("produces walked directories", func(t *testing.T) {
+		fs := testutil.InitFiles(t, map[string]string{
+			"/repo/.gitignore":        "ignored/\n",
+			"/repo/.git/HEAD":         "",
+			"/repo/ignored/file":      "",
+			"/repo/src/Nested/file":   "",
+			"/repo/node_modules/file": "",
+		})
+		linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+		require.Nil(t, newErr)
+
+		dirs, dirsErr := linter.Dirs(".")
+		require.Nil(t, dirsErr)
+		assert.Equal(t, []string{"/repo", "/repo/src", "/repo/src/Nested"}, dirs)
+	})
+}
@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"snekcheck/internal/config"
+	"snekcheck/internal/files"
+	"snekcheck/internal/plugin"
+)
+
+// The maximum number of paths sent to plugins at once.
+const pluginBatchSize = 256
+
+// The running plugins of a single check or fix.
+type plugins []*plugin.Plugin
+
+// Launches the plugins of a configuration.
+// Fails if any plugin cannot be started, stopping those started until then.
+func startPlugins(cfg []config.Plugin) (ps plugins, err error) {
+	for _, p := range cfg {
+		started, startErr := plugin.Start(p.Name, p.Command, p.Dir)
+		if startErr != nil {
+			return nil, errors.Join(startErr, ps.close())
+		}
+		ps = append(ps, started)
+	}
+	return ps, nil
+}
+
+// Launches the plugins of the linter's configuration, if the linter's options allow plugins to run.
+// Otherwise, configured plugins are skipped, recording a warning in the run.
+// Fails if any plugin cannot be started.
+func (l *Linter) startPlugins(r *run) (plugins, error) {
+	if len(l.cfg.Plugins) != 0 && !l.options.Plugins {
+		r.warn(fmt.Errorf("skipped %d configured plugins, which only run when allowed", len(l.cfg.Plugins)))
+		return nil, nil
+	}
+	return startPlugins(l.cfg.Plugins)
+}
+
+// Checks a batch of paths with every plugin, producing their diagnostics keyed by path, in plugin order.
+// The IDs of the plugins' rules are prefixed with the names of the plugins, such as "prefix/required".
+func (ps plugins) check(paths []plugin.Path) (map[string][]Diagnostic, error) {
+	diagnostics := make(map[string][]Diagnostic)
+	for _, p := range ps {
+		pluginDiagnostics, checkErr := p.Check(paths)
+		if checkErr != nil {
+			return nil, checkErr
+		}
+		for _, d := range pluginDiagnostics {
+			rule := p.Name()
+			if d.Rule != "" {
+				rule += "/" + d.Rule
+			}
+			diagnostics[d.Path] = append(diagnostics[d.Path], Diagnostic{Rule: rule, Message: d.Message, Fix: d.Fix})
+		}
+	}
+	return diagnostics, nil
+}
+
+// Stops every plugin, producing their failures.
+func (ps plugins) close() error {
+	var errs []error
+	for _, p := range ps {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Converts a walked path to a path checked by plugins.
+func pluginPath(path files.Path, e entry) plugin.Path {
+	return plugin.Path{Path: absString(path), Dir: e.IsDir()}
+}
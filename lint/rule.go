@@ -0,0 +1,158 @@
+package lint
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"snekcheck/internal/patterns"
+	"strings"
+)
+
+// A Rule validates the names of paths.
+type Rule interface {
+	// The unique ID of the rule, by which it may be toggled in the `rules` section of the configuration file.
+	ID() string
+	// Describes what the rule requires of names.
+	Description() string
+	// Checks an absolute path, producing a diagnostic for each violation.
+	// The file info describes the path itself, without following symbolic links, and is nil for bare names.
+	// Diagnostics default to being Invalid, about the checked path, and produced by the rule.
+	Check(path string, info fs.FileInfo) []Diagnostic
+}
+
+// A Rule that can correct the names it rejects.
+type Fixer interface {
+	Rule
+	// Produces a name that satisfies the rule, if possible.
+	Fix(name string) string
+}
+
+// The built-in rules, sorted by ID.
+// Together, they require names to be POSIX filenames, in snake_case or SCREAMING_SNAKE_CASE, with snake_case extensions.
+var builtinRules = []Rule{caseRule{}, extensionRule{}, posixRule{}}
+
+// Produces the built-in rules, sorted by ID.
+func Rules() []Rule {
+	return slices.Clone(builtinRules)
+}
+
+// Determines if an ID names a built-in rule.
+func IsRule(id string) bool {
+	return slices.ContainsFunc(builtinRules, func(rule Rule) bool {
+		return rule.ID() == id
+	})
+}
+
+// Produces the built-in rules, except those disabled by a set of toggles keyed by ID.
+// Rules are enabled by default.
+func enabledRules(toggles map[string]bool) []Rule {
+	return slices.DeleteFunc(Rules(), func(rule Rule) bool {
+		enabled, ok := toggles[rule.ID()]
+		return ok && !enabled
+	})
+}
+
+// Checks a path against rules, producing their diagnostics.
+func checkRules(rules []Rule, path string, info fs.FileInfo) (diagnostics []Diagnostic) {
+	for _, rule := range rules {
+		for _, d := range rule.Check(path, info) {
+			if d.Kind == "" {
+				d.Kind = Invalid
+			}
+			if d.Path == "" {
+				d.Path = path
+			}
+			if d.Rule == "" {
+				d.Rule = rule.ID()
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return
+}
+
+// Corrects a name with each rule that rejects it, in order, as far as the rules are able.
+// Fixes that would produce an empty name, or no filename at all, are not applied.
+func fixName(rules []Rule, name string) string {
+	for _, rule := range rules {
+		if fixer, ok := rule.(Fixer); ok && len(rule.Check(name, nil)) != 0 {
+			if fixed := fixer.Fix(name); fixed != "" && fixed != "." && fixed != ".." {
+				name = fixed
+			}
+		}
+	}
+	return name
+}
+
+// Splits a name into its stem and its last extension, including the dot.
+func splitExtension(name string) (stem string, extension string) {
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name[:i], name[i:]
+	}
+	return name, ""
+}
+
+// Requires names to be valid POSIX filenames.
+type posixRule struct{}
+
+func (posixRule) ID() string { return "posix" }
+
+func (posixRule) Description() string {
+	return "Names consist of at most 255 letters, digits, periods, underscores and hyphens, and do not start with a hyphen."
+}
+
+func (posixRule) Check(path string, _ fs.FileInfo) []Diagnostic {
+	if name := filepath.Base(path); !patterns.IsPosixFileName(name) {
+		return []Diagnostic{{Message: fmt.Sprintf("%q is not a valid POSIX filename", name)}}
+	}
+	return nil
+}
+
+func (posixRule) Fix(name string) string {
+	return patterns.ToPosixFileName(name)
+}
+
+// Requires the stems of names to be snake_case or SCREAMING_SNAKE_CASE.
+type caseRule struct{}
+
+func (caseRule) ID() string { return "case" }
+
+func (caseRule) Description() string {
+	return "Names, excluding their extension, are snake_case or SCREAMING_SNAKE_CASE."
+}
+
+func (caseRule) Check(path string, _ fs.FileInfo) []Diagnostic {
+	stem, _ := splitExtension(filepath.Base(path))
+	if !patterns.IsSnakeCase(stem) && !patterns.IsScreamingSnakeCase(stem) {
+		return []Diagnostic{{Message: fmt.Sprintf("%q is neither snake_case nor SCREAMING_SNAKE_CASE", stem)}}
+	}
+	return nil
+}
+
+func (caseRule) Fix(name string) string {
+	stem, extension := splitExtension(name)
+	return patterns.ToSnakeCase(stem) + extension
+}
+
+// Requires the extensions of names to be snake_case.
+type extensionRule struct{}
+
+func (extensionRule) ID() string { return "extension" }
+
+func (extensionRule) Description() string {
+	return "The extensions of names are snake_case."
+}
+
+func (extensionRule) Check(path string, _ fs.FileInfo) []Diagnostic {
+	_, extension := splitExtension(filepath.Base(path))
+	if !patterns.IsSnakeCase(extension) {
+		return []Diagnostic{{Message: fmt.Sprintf("extension %q is not snake_case", extension)}}
+	}
+	return nil
+}
+
+func (extensionRule) Fix(name string) string {
+	stem, extension := splitExtension(name)
+	return stem + patterns.ToSnakeCase(extension)
+}
@@ -0,0 +1,54 @@
+package lint_test
+
+import (
+	"snekcheck/lint"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	t.Parallel()
+	t.Run("produces the built-in rules sorted by ID", func(t *testing.T) {
+		var ids []string
+		for _, rule := range lint.Rules() {
+			ids = append(ids, rule.ID())
+			assert.NotEmpty(t, rule.Description())
+		}
+		assert.Equal(t, []string{"case", "extension", "posix"}, ids)
+	})
+	t.Run("diagnoses the aspect of a name that fails", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			input string
+			id    string
+		}{
+			{name: "casing", input: "Snake.go", id: "case"},
+			{name: "extension", input: "snake.PNG", id: "extension"},
+			{name: "length", input: strings.Repeat("s", 256), id: "posix"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				for _, rule := range lint.Rules() {
+					assert.Equal(t, rule.ID() == tc.id, len(rule.Check("/"+tc.input, nil)) != 0, rule.ID())
+				}
+			})
+		}
+	})
+	t.Run("fixes the names it rejects", func(t *testing.T) {
+		for _, rule := range lint.Rules() {
+			fixer, ok := rule.(lint.Fixer)
+			if !ok {
+				continue
+			}
+			t.Run(rule.ID(), func(t *testing.T) {
+				assert.Empty(t, rule.Check(fixer.Fix("Snake Case!.PNG"), nil))
+			})
+		}
+	})
+	t.Run("identifies built-in rules", func(t *testing.T) {
+		assert.True(t, lint.IsRule("posix"))
+		assert.False(t, lint.IsRule("kebab"))
+	})
+}
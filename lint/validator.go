@@ -0,0 +1,20 @@
+package lint
+
+import "slices"
+
+// Determines if a filename is valid according to every built-in rule.
+func IsValid(name string) bool {
+	return len(name) > 0 && len(checkRules(builtinRules, name, nil)) == 0
+}
+
+// Determines if a filename is valid according to the rules enabled by the linter's configuration.
+// Filenames mandated by the enabled ecosystems, such as Makefile, are valid.
+func (l *Linter) IsValid(name string) bool {
+	r := newRepository(l.cfg)
+	return len(name) > 0 && (r.allowlist.Contains(name) || len(checkRules(r.rules, name, nil)) == 0)
+}
+
+// Produces the short reference name prefixes that are exempt from validation, according to the linter's configuration.
+func (l *Linter) AllowedRefs() []string {
+	return slices.Clone(l.cfg.Refs.Allow)
+}
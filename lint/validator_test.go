@@ -0,0 +1,75 @@
+package lint_test
+
+import (
+	"snekcheck/internal/testutil"
+	"snekcheck/lint"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValid(t *testing.T) {
+	t.Parallel()
+	t.Run("identifies valid file names", func(t *testing.T) {
+		testCases := []string{
+			"main.go",
+			"flake.nix",
+			"LICENSE",
+			"README.md",
+			"snake_test.go",
+		}
+		for _, input := range testCases {
+			t.Run(input, func(t *testing.T) {
+				assert.True(t, lint.IsValid(input))
+			})
+		}
+	})
+	t.Run("identifies invalid file names", func(t *testing.T) {
+		testCases := []string{
+			"Snake",
+			"snake case 123",
+			"snake-case",
+			"Readme.md",
+			"snake.PNG",
+		}
+		for _, input := range testCases {
+			t.Run(input, func(t *testing.T) {
+				assert.False(t, lint.IsValid(input))
+			})
+		}
+	})
+}
+
+func TestLinterIsValid(t *testing.T) {
+	t.Parallel()
+	fs := testutil.InitFiles(t, map[string]string{
+		"/repo/.snekcheck.yaml": "rules:\n  extension: false\nconventions:\n  make: false\n",
+	})
+	linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+	require.Nil(t, newErr)
+	t.Run("identifies valid file names", func(t *testing.T) {
+		for _, input := range []string{"main.go", "snake.PNG", "Dockerfile"} {
+			t.Run(input, func(t *testing.T) {
+				assert.True(t, linter.IsValid(input))
+			})
+		}
+	})
+	t.Run("identifies invalid file names", func(t *testing.T) {
+		for _, input := range []string{"", "Snake.go", "Makefile"} {
+			t.Run(input, func(t *testing.T) {
+				assert.False(t, linter.IsValid(input))
+			})
+		}
+	})
+}
+
+func TestAllowedRefs(t *testing.T) {
+	t.Parallel()
+	fs := testutil.InitFiles(t, map[string]string{
+		"/repo/.snekcheck.yaml": "refs:\n  allow: [release/, v]\n",
+	})
+	linter, newErr := lint.New(lint.Options{FileSystem: fs, Dir: "/repo"})
+	require.Nil(t, newErr)
+	assert.Equal(t, []string{"release/", "v"}, linter.AllowedRefs())
+}
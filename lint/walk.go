@@ -0,0 +1,207 @@
+package lint
+
+import (
+	"fmt"
+	"io/fs"
+	"iter"
+	"runtime"
+	"slices"
+	"snekcheck/internal/cache"
+	"snekcheck/internal/config"
+	"snekcheck/internal/conventions"
+	"snekcheck/internal/files"
+
+	"github.com/go-git/go-billy/v5"
+)
+
+// Iterates over the file trees rooted at each path, skipping ignored and excluded paths.
+// Symbolic links are produced as links, and are only descended into if the configuration follows them,
+// except for walked paths that are themselves symbolic links to directories, which are always descended into.
+// Directories are read concurrently, as configured, though paths are always produced in sorted, depth-first order.
+// When the configuration includes glob patterns, paths that do not match are marked as such, so that they are not validated.
+// The ignore rules of the directories enclosing each path apply to it, from the root of its repository,
+// or else from the linter's directory.
+// Nested repositories, such as Git submodules, are walked with their own ignore rules and configuration,
+// except for the ignore settings and glob patterns of the configuration, which apply throughout the walk.
+// The rules, conventions, glob patterns and submodules mode of a nested repository's configuration apply within it,
+// while its other settings, such as plugins, are disregarded.
+// Git submodules may be skipped instead.
+// Unmodified directories are not read again when a cache is given, unless symbolic links are followed.
+// Stops upon failure to load the configuration of a nested repository, recording the failure in the run.
+func (l *Linter) walk(cfg config.Config, c *cache.Cache, paths []files.Path, run *run) iter.Seq2[files.Path, entry] {
+	fileSystem := l.fs
+	return func(yield func(files.Path, entry) bool) {
+		ignore := files.NewIgnore(fileSystem, files.IgnoreOptions{
+			Global:            loadGlobalGitIgnore(fileSystem, run),
+			Extra:             loadIgnoreFiles(fileSystem, cfg.Ignore.Files, run),
+			NoGitIgnore:       cfg.Ignore.NoGitIgnore,
+			NoDefaultExcludes: cfg.Ignore.NoDefaultExcludes,
+		})
+		repositories := files.NewScopeStack(newRepository(cfg))
+		options := files.TreeOptions{FollowSymlinks: cfg.FollowSymlinks, Jobs: cfg.Jobs}
+		if options.Jobs == 0 {
+			options.Jobs = runtime.NumCPU()
+		}
+		// Cached entries cannot identify the targets of followed symbolic links, which break cycles.
+		if c != nil && !cfg.FollowSymlinks {
+			options.Cache = c
+		}
+
+		// Enters a directory, scoping its ignore rules and repository state to it.
+		// Fails if the directory is a nested repository whose configuration cannot be loaded.
+		enter := func(dir files.Path) error {
+			r := repositories.Get(dir)
+			if ignore.Enter(dir) {
+				if r.inRepository {
+					nestedCfg, configErr := l.loadConfig(dir)
+					if configErr != nil {
+						return configErr
+					}
+					r = newRepository(nestedCfg)
+					r.nested = true
+				}
+				r.inRepository = true
+				r.submodules = nil
+			}
+			r.submodules = slices.Concat(r.submodules, parseGitModules(fileSystem, dir))
+			repositories.Push(dir, r)
+			return nil
+		}
+		match := func(path files.Path, isDir bool) bool {
+			r := repositories.Get(path)
+			if files.MatchGlobs(cfg.Exclude, path) || files.MatchGlobs(r.cfg.Exclude, path) {
+				return false
+			}
+			if isDir && r.cfg.Submodules == config.SubmodulesSkip && r.isSubmodule(path) {
+				return false
+			}
+			return !ignore.Match(path, isDir)
+		}
+
+		for _, path := range paths {
+			for _, dir := range l.parents(path) {
+				if run.err = enter(dir); run.err != nil {
+					return
+				}
+			}
+			for path, fileInfo := range files.IterTree(fileSystem, match, path, options) {
+				r := repositories.Get(path)
+				e := entry{
+					FileInfo:   fileInfo,
+					included:   isIncluded(cfg, path) && isIncluded(r.cfg, path),
+					attributes: ignore.Attributes(path),
+					dangling:   files.IsDanglingSymlink(fileSystem, path, fileInfo),
+					repository: r,
+				}
+				if fileInfo.IsDir() {
+					if run.err = enter(path); run.err != nil {
+						return
+					}
+					e.expired = ignore.Expired(path)
+				}
+
+				if !yield(path, e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// A path produced by walking a file tree.
+type entry struct {
+	fs.FileInfo
+	// Whether the path matches the include patterns of the configuration, if any.
+	included   bool
+	attributes files.Attributes
+	// Whether the path is a symbolic link whose target does not exist.
+	dangling bool
+	// The expired time-boxed entries of a directory's own .snekcheckignore file.
+	expired []files.Suppression
+	// The repository enclosing the path, whose configuration applies to it.
+	repository repository
+}
+
+// Produces the directories entered before walking a path, so that their ignore rules and configurations apply to it.
+// These are the directories from the root of the innermost repository containing the path, or else from the linter's directory,
+// down to the path's parent.
+func (l *Linter) parents(path files.Path) []files.Path {
+	if parents := files.RepositoryParents(l.fs, path); len(parents) != 0 {
+		return parents
+	}
+	var parents []files.Path
+	if len(path) > len(l.dir) && l.dir.Contains(path) {
+		// Prefixes are capped so that appending to them never overwrites the rest of the path.
+		for i := len(l.dir); i < len(path); i++ {
+			parents = append(parents, path[:i:i])
+		}
+	}
+	return parents
+}
+
+// Determines if a path matches the include patterns of a configuration, if any.
+func isIncluded(cfg config.Config, path files.Path) bool {
+	return len(cfg.Include) == 0 || files.MatchGlobs(cfg.Include, path)
+}
+
+// The state of the repository enclosing a directory.
+type repository struct {
+	// Whether the directory is within a repository at all.
+	inRepository bool
+	// Whether the repository is nested within the walked repository, and so has its own configuration.
+	nested     bool
+	submodules []files.Path
+	cfg        config.Config
+	// The rules enabled by the configuration.
+	rules []Rule
+	// The filenames mandated by the ecosystems enabled by the configuration.
+	allowlist conventions.Allowlist
+}
+
+// Constructs the state of a repository with a configuration.
+func newRepository(cfg config.Config) repository {
+	return repository{cfg: cfg, rules: enabledRules(cfg.Rules), allowlist: conventions.NewAllowlist(cfg.Conventions)}
+}
+
+// Determines if a path is the root of one of the repository's submodules.
+func (r repository) isSubmodule(path files.Path) bool {
+	return slices.ContainsFunc(r.submodules, func(submodule files.Path) bool {
+		return slices.Equal(submodule, path)
+	})
+}
+
+// Parses the submodule paths declared in a single directory.
+// Produces an empty list of paths upon failure.
+func parseGitModules(fs billy.Filesystem, path files.Path) []files.Path {
+	submodules, modulesErr := files.ParseGitModules(fs, path)
+	if modulesErr != nil {
+		submodules = nil
+	}
+	return submodules
+}
+
+// Parses the patterns of each file with gitignore syntax.
+// Skips files that fail to parse, recording a warning in the run.
+func loadIgnoreFiles(fs billy.Filesystem, paths []string, run *run) files.GitIgnore {
+	var patterns files.GitIgnore
+	for _, path := range paths {
+		filePatterns, parseErr := files.ParseIgnoreFile(fs, files.NewPath(path))
+		if parseErr != nil {
+			run.warn(fmt.Errorf("failed to load ignore file %s: %w", path, parseErr))
+			continue
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	return patterns
+}
+
+// Parses the list of global gitignore patterns.
+// Produces an empty list of patterns upon failure, recording a warning in the run.
+func loadGlobalGitIgnore(fs billy.Filesystem, run *run) files.GitIgnore {
+	globalIgnorePatterns, ignoreErr := files.GlobalGitIgnorePatterns(fs)
+	if ignoreErr != nil {
+		run.warn(ignoreErr)
+		globalIgnorePatterns = nil
+	}
+	return globalIgnorePatterns
+}
@@ -22,4 +22,73 @@ Describe "check"
       The file "$root"/InVaLiD should be exist
     End
   End
+
+  Context "with an invalid file listed in a .snekcheckignore file"
+    create_ignored_file() {
+      touch "$root"/InVaLiD
+      echo "InVaLiD" > "$root"/.snekcheckignore
+    }
+    BeforeEach "create_ignored_file"
+
+    It "succeeds"
+      When call "$bin" "$root"
+      The status should be success
+    End
+  End
+
+  Context "with an invalid file listed in an ignore file"
+    create_ignored_file() {
+      touch "$root"/InVaLiD
+      echo "InVaLiD" > "$root"/.dockerignore
+    }
+    BeforeEach "create_ignored_file"
+
+    It "fails without the ignore file"
+      When call "$bin" "$root"
+      The status should be failure
+    End
+
+    It "succeeds with the ignore file"
+      When call "$bin" --ignore-file "$root"/.dockerignore "$root"
+      The status should be success
+    End
+  End
+
+  Context "with an invalid file matching a glob pattern"
+    create_invalid_file() {
+      mkdir "$root"/testdata
+      touch "$root"/testdata/InVaLiD
+    }
+    BeforeEach "create_invalid_file"
+
+    It "succeeds when the file is excluded"
+      When call "$bin" --exclude "$root/testdata/**" "$root"
+      The status should be success
+    End
+
+    It "succeeds when the file is not included"
+      When call "$bin" --include "**/*.py" "$root"
+      The status should be success
+    End
+  End
+
+  Context "with a cache from a previous run"
+    run_cached() {
+      touch "$root"/valid
+      touch -d '1 hour ago' "$root"
+      "$bin" "$root" 2> /dev/null
+      touch "$root"/InVaLiD
+    }
+    BeforeEach "run_cached"
+
+    It "fails once an invalid file is added"
+      When call "$bin" "$root"
+      The status should be failure
+    End
+
+    It "fails without the cache"
+      When call "$bin" --no-cache "$root"
+      The status should be failure
+    End
+  End
 End